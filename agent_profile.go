@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AgentProfile describes a named agent persona: the system prompt it runs
+// with and the subset of tools it is allowed to call. Profiles are how we
+// avoid exposing every tool (including destructive ones added later) to
+// every conversation by default.
+type AgentProfile struct {
+	Name         string   `yaml:"name" json:"name"`
+	SystemPrompt string   `yaml:"system_prompt" json:"system_prompt"`
+	Tools        []string `yaml:"tools" json:"tools"`
+}
+
+// defaultCoderAgent is used when no config file is present and when
+// --agent is left unset.
+func defaultCoderAgent() AgentProfile {
+	return AgentProfile{
+		Name:         "coder",
+		SystemPrompt: "You are Praktor, a terminal-based coding agent. Use the available tools to read, explore, and edit files in the working directory on the user's behalf.",
+		Tools:        []string{"read_file", "list_files", "edit_file", "modify_file"},
+	}
+}
+
+// agentsConfigPath returns the location agents.yaml/agents.json is loaded
+// from: $XDG_CONFIG_HOME/praktor/agents.yaml, falling back to the OS
+// default config dir when XDG_CONFIG_HOME is unset.
+func agentsConfigPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = dir
+	}
+	return filepath.Join(configHome, "praktor", "agents.yaml"), nil
+}
+
+// loadAgentProfiles reads agent profiles from the config path, falling
+// back to a JSON file of the same name with a .json extension. Missing
+// files are not an error: the default "coder" agent is always available.
+func loadAgentProfiles() ([]AgentProfile, error) {
+	profiles := []AgentProfile{defaultCoderAgent()}
+
+	path, err := agentsConfigPath()
+	if err != nil {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			jsonPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".json"
+			data, err = os.ReadFile(jsonPath)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return profiles, nil
+				}
+				return nil, err
+			}
+			path = jsonPath
+		} else {
+			return nil, err
+		}
+	}
+
+	var configured []AgentProfile
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &configured)
+	} else {
+		err = yaml.Unmarshal(data, &configured)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing agent config %s: %w", path, err)
+	}
+
+	for _, p := range configured {
+		profiles = replaceOrAppendProfile(profiles, p)
+	}
+
+	return profiles, nil
+}
+
+func replaceOrAppendProfile(profiles []AgentProfile, p AgentProfile) []AgentProfile {
+	for i, existing := range profiles {
+		if existing.Name == p.Name {
+			profiles[i] = p
+			return profiles
+		}
+	}
+	return append(profiles, p)
+}
+
+// findAgentProfile looks up a profile by name, returning an error listing
+// the known agents if it isn't found.
+func findAgentProfile(profiles []AgentProfile, name string) (AgentProfile, error) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	return AgentProfile{}, fmt.Errorf("unknown agent %q (known agents: %s)", name, strings.Join(names, ", "))
+}
+
+// toolsForProfile filters the full tool registry down to the names listed
+// in the profile, preserving registry order.
+func toolsForProfile(all []ToolDefinition, profile AgentProfile) []ToolDefinition {
+	allowed := make(map[string]bool, len(profile.Tools))
+	for _, name := range profile.Tools {
+		allowed[name] = true
+	}
+
+	var filtered []ToolDefinition
+	for _, tool := range all {
+		if allowed[tool.Name] {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withConfigHome(t *testing.T, dir string) {
+	t.Helper()
+	prev, had := os.LookupEnv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	t.Cleanup(func() {
+		if had {
+			os.Setenv("XDG_CONFIG_HOME", prev)
+		} else {
+			os.Unsetenv("XDG_CONFIG_HOME")
+		}
+	})
+}
+
+func TestLoadAgentProfilesNoConfigReturnsDefault(t *testing.T) {
+	withConfigHome(t, t.TempDir())
+
+	profiles, err := loadAgentProfiles()
+	if err != nil {
+		t.Fatalf("loadAgentProfiles: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "coder" {
+		t.Fatalf("got %+v, want only the default coder profile", profiles)
+	}
+}
+
+func TestLoadAgentProfilesYAMLAddsAndOverrides(t *testing.T) {
+	configHome := t.TempDir()
+	withConfigHome(t, configHome)
+
+	dir := filepath.Join(configHome, "praktor")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	yaml := `
+- name: coder
+  system_prompt: overridden prompt
+  tools: [edit_file]
+- name: reviewer
+  system_prompt: You review diffs.
+  tools: [read_file]
+`
+	if err := os.WriteFile(filepath.Join(dir, "agents.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	profiles, err := loadAgentProfiles()
+	if err != nil {
+		t.Fatalf("loadAgentProfiles: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("got %d profiles, want 2 (overridden coder + reviewer)", len(profiles))
+	}
+
+	coder, err := findAgentProfile(profiles, "coder")
+	if err != nil {
+		t.Fatalf("findAgentProfile(coder): %v", err)
+	}
+	if coder.SystemPrompt != "overridden prompt" {
+		t.Errorf("coder.SystemPrompt = %q, want the configured override", coder.SystemPrompt)
+	}
+
+	if _, err := findAgentProfile(profiles, "reviewer"); err != nil {
+		t.Errorf("findAgentProfile(reviewer): %v", err)
+	}
+}
+
+func TestLoadAgentProfilesFallsBackToJSON(t *testing.T) {
+	configHome := t.TempDir()
+	withConfigHome(t, configHome)
+
+	dir := filepath.Join(configHome, "praktor")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	jsonConfig := `[{"name": "reviewer", "system_prompt": "You review diffs.", "tools": ["read_file"]}]`
+	if err := os.WriteFile(filepath.Join(dir, "agents.json"), []byte(jsonConfig), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	profiles, err := loadAgentProfiles()
+	if err != nil {
+		t.Fatalf("loadAgentProfiles: %v", err)
+	}
+	if len(profiles) != 2 {
+		t.Fatalf("got %d profiles, want 2 (default coder + json reviewer)", len(profiles))
+	}
+	if _, err := findAgentProfile(profiles, "reviewer"); err != nil {
+		t.Errorf("findAgentProfile(reviewer): %v", err)
+	}
+}
+
+func TestFindAgentProfileUnknownListsKnownNames(t *testing.T) {
+	profiles := []AgentProfile{defaultCoderAgent(), {Name: "reviewer"}}
+
+	_, err := findAgentProfile(profiles, "ghost")
+	if err == nil {
+		t.Fatal("expected error for unknown agent, got nil")
+	}
+	if got := err.Error(); got == "" {
+		t.Fatal("expected a non-empty error message")
+	}
+}
+
+func TestReplaceOrAppendProfile(t *testing.T) {
+	profiles := []AgentProfile{defaultCoderAgent()}
+
+	profiles = replaceOrAppendProfile(profiles, AgentProfile{Name: "coder", SystemPrompt: "new prompt"})
+	if len(profiles) != 1 || profiles[0].SystemPrompt != "new prompt" {
+		t.Fatalf("got %+v, want coder replaced in place", profiles)
+	}
+
+	profiles = replaceOrAppendProfile(profiles, AgentProfile{Name: "reviewer"})
+	if len(profiles) != 2 || profiles[1].Name != "reviewer" {
+		t.Fatalf("got %+v, want reviewer appended", profiles)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// conversationSubcommands lists the `praktor <subcommand>` names handled by
+// runConversationCommand, as opposed to the bare `praktor` interactive REPL.
+var conversationSubcommands = map[string]bool{
+	"new":    true,
+	"reply":  true,
+	"view":   true,
+	"ls":     true,
+	"rm":     true,
+	"branch": true,
+}
+
+func runConversationCommand(name string, args []string) error {
+	storePath, err := defaultStorePath()
+	if err != nil {
+		return err
+	}
+	store, err := OpenSQLiteStore(storePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	switch name {
+	case "new":
+		return cmdNew(store, args)
+	case "reply":
+		return cmdReply(store, args)
+	case "view":
+		return cmdView(store, args)
+	case "ls":
+		return cmdLs(store, args)
+	case "rm":
+		return cmdRm(store, args)
+	case "branch":
+		return cmdBranch(store, args)
+	default:
+		return fmt.Errorf("unknown subcommand %q", name)
+	}
+}
+
+func cmdNew(store ConversationStore, args []string) error {
+	title := "untitled conversation"
+	if len(args) > 0 {
+		title = args[0]
+	}
+
+	conv, err := store.CreateConversation(title)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(conv.ID)
+	return nil
+}
+
+func cmdReply(store ConversationStore, args []string) error {
+	fs := flag.NewFlagSet("reply", flag.ExitOnError)
+	agentName := fs.String("agent", "coder", "name of the agent profile to run")
+	providerFlag := fs.String("provider", "", "backend to use: openrouter, anthropic, ollama, openai, or gemini (default: $PRAKTOR_PROVIDER, else sniffed from *_API_KEY env vars)")
+	yes := fs.Bool("yes", false, "skip confirmation prompts for mutating tool calls")
+	workspaceRootFlag := fs.String("workspace-root", ".", "directory tool calls are sandboxed to")
+	contextWindow := fs.Int("context-window", 0, "override the model's context window size in tokens (0 = use the built-in default for the model)")
+	summarizeThreshold := fs.Float64("summarize-threshold", defaultSummarizeThreshold, "summarize instead of silently dropping once pruning would discard this fraction of context")
+	allowTools := stringSetFlag{}
+	denyTools := stringSetFlag{}
+	fs.Var(&allowTools, "allow-tool", "tool name to pre-approve without prompting (repeatable)")
+	fs.Var(&denyTools, "deny-tool", "tool name to always refuse (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	rest := fs.Args()
+	if len(rest) < 2 {
+		return fmt.Errorf("usage: praktor reply <conversation-id> <message>")
+	}
+	conversationID, message := rest[0], rest[1]
+
+	if err := setWorkspaceRoot(*workspaceRootFlag); err != nil {
+		return err
+	}
+
+	conv, err := store.GetConversation(conversationID)
+	if err != nil {
+		return err
+	}
+
+	agent, err := newScriptedAgent(store, conv.ID, conv.HeadID, *agentName, *providerFlag, AgentOptions{
+		AutoApprove:        *yes,
+		AllowTools:         allowTools.values,
+		DenyTools:          denyTools.values,
+		ContextWindow:      *contextWindow,
+		SummarizeThreshold: *summarizeThreshold,
+	})
+	if err != nil {
+		return err
+	}
+
+	responseText, err := agent.Reply(context.TODO(), message)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(responseText)
+	return nil
+}
+
+func cmdView(store ConversationStore, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: praktor view <conversation-id>")
+	}
+
+	conv, err := store.GetConversation(args[0])
+	if err != nil {
+		return err
+	}
+
+	history, err := store.History(conv.HeadID)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range history {
+		fmt.Printf("[%s] %s: %s\n", msg.ID, msg.Role, msg.Content)
+	}
+	return nil
+}
+
+func cmdLs(store ConversationStore, args []string) error {
+	conversations, err := store.ListConversations()
+	if err != nil {
+		return err
+	}
+
+	for _, conv := range conversations {
+		fmt.Printf("%s\t%s\t%s\n", conv.ID, conv.CreatedAt.Format("2006-01-02 15:04"), conv.Title)
+	}
+	return nil
+}
+
+func cmdRm(store ConversationStore, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: praktor rm <conversation-id>")
+	}
+	return store.DeleteConversation(args[0])
+}
+
+// cmdBranch edits a prior message by forking a new sibling with the given
+// content, retaining the original message and its descendants untouched.
+// Only user messages can be branched: forking a tool-call assistant
+// message or a tool-result message would split a tool_use/tool_result
+// pair across branches, corrupting the pairing groupMessageTurns relies
+// on.
+func cmdBranch(store ConversationStore, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: praktor branch <conversation-id> <message-id> <new-content>")
+	}
+	conversationID, messageID, newContent := args[0], args[1], args[2]
+
+	history, err := store.History(messageID)
+	if err != nil {
+		return err
+	}
+	if len(history) == 0 {
+		return errMessageNotFound(messageID)
+	}
+	original := history[len(history)-1]
+	if original.ConversationID != conversationID {
+		return fmt.Errorf("message %q belongs to conversation %q, not %q", messageID, original.ConversationID, conversationID)
+	}
+	if original.Role != "user" {
+		return fmt.Errorf("message %q is a %q message, not a user message; only user messages can be branched", messageID, original.Role)
+	}
+
+	forked, err := store.AppendMessage(StoredMessage{
+		ConversationID: conversationID,
+		ParentID:       original.ParentID,
+		Role:           original.Role,
+		Content:        newContent,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetHead(conversationID, forked.ID); err != nil {
+		return err
+	}
+
+	fmt.Println(forked.ID)
+	return nil
+}
+
+// newScriptedAgent builds an Agent for non-interactive subcommands
+// (currently just `reply`), picking up the requested agent profile the
+// same way the interactive REPL does.
+func newScriptedAgent(store ConversationStore, conversationID, headID, agentName, providerType string, opts AgentOptions) (*Agent, error) {
+	provider, err := getAPIProvider(resolveProviderType(providerType))
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := loadAgentProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := findAgentProfile(profiles, agentName)
+	if err != nil {
+		return nil, err
+	}
+
+	allTools := []ToolDefinition{
+		ReadFileDefinition,
+		ListFilesDefinition,
+		EditFileDefinition,
+		ModifyFileDefinition,
+	}
+
+	opts.SystemPrompt = profile.SystemPrompt
+	opts.Store = store
+	opts.ConversationID = conversationID
+
+	agent := NewAgent(provider, nil, toolsForProfile(allTools, profile), opts)
+	agent.headID = headID
+	return agent, nil
+}
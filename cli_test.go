@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func openTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestCmdBranchForksANewSiblingAndMovesHead(t *testing.T) {
+	store := openTestStore(t)
+
+	conv, err := store.CreateConversation("test")
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	original, err := store.AppendMessage(StoredMessage{ConversationID: conv.ID, Role: "user", Content: "hello"})
+	if err != nil {
+		t.Fatalf("AppendMessage: %v", err)
+	}
+
+	if err := cmdBranch(store, []string{conv.ID, original.ID, "hello, edited"}); err != nil {
+		t.Fatalf("cmdBranch: %v", err)
+	}
+
+	got, err := store.GetConversation(conv.ID)
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if got.HeadID == original.ID {
+		t.Fatal("head wasn't moved to the forked message")
+	}
+
+	forked, err := store.getMessage(got.HeadID)
+	if err != nil {
+		t.Fatalf("getMessage(forked): %v", err)
+	}
+	if forked.ParentID != original.ParentID {
+		t.Errorf("forked.ParentID = %q, want %q (original's parent)", forked.ParentID, original.ParentID)
+	}
+	if forked.Content != "hello, edited" {
+		t.Errorf("forked.Content = %q, want %q", forked.Content, "hello, edited")
+	}
+
+	stillThere, err := store.getMessage(original.ID)
+	if err != nil {
+		t.Fatalf("original message should still exist: %v", err)
+	}
+	if stillThere.Content != "hello" {
+		t.Errorf("original message was mutated, got content %q", stillThere.Content)
+	}
+}
+
+func TestCmdBranchRejectsNonUserMessage(t *testing.T) {
+	store := openTestStore(t)
+
+	conv, err := store.CreateConversation("test")
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	assistantMsg, err := store.AppendMessage(StoredMessage{
+		ConversationID: conv.ID,
+		Role:           "assistant",
+		ToolCalls:      []ToolCall{{ID: "call_1"}},
+	})
+	if err != nil {
+		t.Fatalf("AppendMessage: %v", err)
+	}
+
+	if err := cmdBranch(store, []string{conv.ID, assistantMsg.ID, "replacement"}); err == nil {
+		t.Fatal("expected cmdBranch to reject branching a non-user message, got nil error")
+	}
+}
+
+// TestCmdReplyRoundTrip drives cmdReply against a fake OpenAI-compatible
+// server and a real SQLite store, and asserts both the returned text and
+// the store's history reflect the user/assistant turn.
+func TestCmdReplyRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices":[{"delta":{"content":"hi there"}}]}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_BASE_URL", server.URL)
+
+	store := openTestStore(t)
+	conv, err := store.CreateConversation("test")
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+
+	if err := cmdReply(store, []string{"-provider=openai", conv.ID, "hello"}); err != nil {
+		t.Fatalf("cmdReply: %v", err)
+	}
+
+	got, err := store.GetConversation(conv.ID)
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	history, err := store.History(got.HeadID)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d messages in history, want 2 (user + assistant)", len(history))
+	}
+	if history[0].Role != "user" || history[0].Content != "hello" {
+		t.Errorf("history[0] = %+v, want the user message", history[0])
+	}
+	if history[1].Role != "assistant" || history[1].Content != "hi there" {
+		t.Errorf("history[1] = %+v, want the assistant's reply", history[1])
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxTokens is the max_tokens requested on a normal inference call.
+// It doubles as the headroom subtracted from a model's context window when
+// deciding whether history needs pruning.
+const defaultMaxTokens = 4096
+
+// defaultContextWindow is used for models not listed in
+// modelContextWindows.
+const defaultContextWindow = 128000
+
+// defaultSummarizeThreshold is used when --summarize-threshold is left
+// unset: if pruning would discard more than this fraction of the
+// conversation's estimated tokens, summarize the dropped prefix instead of
+// silently discarding it.
+const defaultSummarizeThreshold = 0.3
+
+// modelContextWindows lists approximate context window sizes (in tokens)
+// for models this CLI talks to by default. Unlisted models fall back to
+// defaultContextWindow.
+var modelContextWindows = map[string]int{
+	"claude-sonnet-4-20250514":    200000,
+	"anthropic/claude-sonnet-4.5": 200000,
+	"gpt-4o":                      128000,
+	"gemini-2.0-flash":            1000000,
+	"qwen2.5-coder":               32000,
+}
+
+// contextWindowFor returns override if set, else the registry entry for
+// model, else defaultContextWindow.
+func contextWindowFor(model string, override int) int {
+	if override > 0 {
+		return override
+	}
+	if w, ok := modelContextWindows[model]; ok {
+		return w
+	}
+	return defaultContextWindow
+}
+
+// estimateTokens approximates a ChatMessage's token count as len/4, the
+// standard rule-of-thumb fallback for providers where we don't have a
+// tiktoken-compatible tokenizer wired in. Tool-call name/arguments count
+// toward the estimate since the model has to read them too.
+func estimateTokens(msg ChatMessage) int {
+	n := len(msg.Content)
+	for _, tc := range msg.ToolCalls {
+		n += len(tc.Function.Name) + len(tc.Function.Arguments)
+	}
+	return n/4 + 1
+}
+
+// messageGroup is one or more StoredMessages that must be pruned together:
+// an assistant message with tool calls plus the tool-result messages that
+// answer them, or a single plain user/assistant message.
+type messageGroup []StoredMessage
+
+func (g messageGroup) tokens() int {
+	total := 0
+	for _, m := range g {
+		total += estimateTokens(ChatMessage{Content: m.Content, ToolCalls: m.ToolCalls})
+	}
+	return total
+}
+
+// lastID returns the StoredMessage ID of the group's final message, used to
+// mark how far a cached summary reaches into history.
+func (g messageGroup) lastID() string {
+	return g[len(g)-1].ID
+}
+
+// groupMessageTurns splits history into messageGroups, keeping each
+// assistant tool-call message together with the tool-result messages that
+// immediately follow it so Anthropic's strict tool_use/tool_result pairing
+// survives pruning.
+func groupMessageTurns(messages []StoredMessage) []messageGroup {
+	var groups []messageGroup
+	for i := 0; i < len(messages); i++ {
+		group := messageGroup{messages[i]}
+		if len(messages[i].ToolCalls) > 0 {
+			for i+1 < len(messages) && messages[i+1].Role == "tool" {
+				i++
+				group = append(group, messages[i])
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func flattenGroups(groups []messageGroup) []StoredMessage {
+	var messages []StoredMessage
+	for _, g := range groups {
+		messages = append(messages, g...)
+	}
+	return messages
+}
+
+// fitContextWindow trims history so its estimated token total, plus
+// headroom for the reply, fits within the agent's configured context
+// window. Messages are dropped oldest-group-first, where a group is a
+// tool-call/tool-result pair kept intact. If the prefix that would need
+// dropping is more than summarizeThreshold of the conversation, it's
+// replaced with a single synthetic assistant message summarizing it
+// instead of being silently discarded.
+//
+// The summary is cached on the Agent (summarizedThroughID/cachedSummary):
+// once a boundary has been summarized, later calls only summarize the
+// newly-dropped delta past that boundary instead of re-summarizing the
+// whole, ever-growing dropped prefix from scratch.
+func (a *Agent) fitContextWindow(ctx context.Context, history []StoredMessage) ([]ChatMessage, error) {
+	limit := contextWindowFor(a.provider.Model, a.contextWindow)
+	budget := limit - defaultMaxTokens
+
+	total := 0
+	for _, m := range history {
+		total += estimateTokens(ChatMessage{Content: m.Content, ToolCalls: m.ToolCalls})
+	}
+	if total <= budget || len(history) == 0 {
+		return toChatMessages(history), nil
+	}
+
+	groups := groupMessageTurns(history)
+
+	keep := len(groups)
+	remaining := total
+	for keep > 1 && remaining > budget {
+		remaining -= groups[len(groups)-keep].tokens()
+		keep--
+	}
+
+	dropped := groups[:len(groups)-keep]
+	retained := flattenGroups(groups[len(groups)-keep:])
+	droppedTokens := total - remaining
+
+	fmt.Printf("\u001b[90mcontext\u001b[0m: %d/%d tokens estimated, dropping %d older message(s) (~%d tokens)\n",
+		total, limit, len(flattenGroups(dropped)), droppedTokens)
+
+	if len(dropped) == 0 {
+		return toChatMessages(retained), nil
+	}
+
+	if float64(droppedTokens)/float64(total) <= a.summarizeThreshold {
+		return toChatMessages(retained), nil
+	}
+
+	toSummarize, carried := a.deltaSince(dropped)
+
+	summary := a.cachedSummary
+	if len(toSummarize) > 0 {
+		fmt.Printf("\u001b[90mcontext\u001b[0m: summarizing dropped messages (over %.0f%% of context)\n", a.summarizeThreshold*100)
+		var err error
+		summary, err = a.summarize(ctx, toSummarize, carried)
+		if err != nil {
+			return nil, fmt.Errorf("summarizing pruned context: %w", err)
+		}
+	}
+
+	a.summarizedThroughID = dropped[len(dropped)-1].lastID()
+	a.cachedSummary = summary
+
+	return append([]ChatMessage{summary}, toChatMessages(retained)...), nil
+}
+
+// deltaSince splits dropped into the groups already folded into
+// a.cachedSummary and the newly-dropped groups past a.summarizedThroughID
+// that still need summarizing (toSummarize). carried is the previous
+// cached summary, passed back so the model can update it with the new
+// delta instead of starting over; it's nil if nothing is cached yet, or
+// if the cached boundary isn't found in dropped (e.g. a branch switch
+// changed the lineage), in which case the whole dropped range is treated
+// as new.
+func (a *Agent) deltaSince(dropped []messageGroup) (toSummarize []StoredMessage, carried *ChatMessage) {
+	if a.summarizedThroughID == "" {
+		return flattenGroups(dropped), nil
+	}
+
+	for i, g := range dropped {
+		if g.lastID() == a.summarizedThroughID {
+			return flattenGroups(dropped[i+1:]), &a.cachedSummary
+		}
+	}
+
+	return flattenGroups(dropped), nil
+}
+
+// summarize asks the same provider, in a single non-streaming call, to
+// condense the messages being dropped from context into one paragraph. If
+// carried is non-nil, it's the previously cached summary, included so the
+// model updates it with the new delta rather than starting over. The
+// result becomes a synthetic assistant message prepended to whatever
+// history is retained.
+func (a *Agent) summarize(ctx context.Context, dropped []StoredMessage, carried *ChatMessage) (ChatMessage, error) {
+	provider, err := lookupProvider(a.provider.ProviderType)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	prompt := ChatMessage{
+		Role: "user",
+		Content: "Summarize the conversation above in a short paragraph, preserving important facts, " +
+			"decisions, and file paths mentioned. This summary will replace the original messages in " +
+			"the conversation history, so do not omit anything another assistant would need to continue the work.",
+	}
+
+	var messages []ChatMessage
+	if carried != nil {
+		messages = append(messages, *carried)
+	}
+	messages = append(messages, toChatMessages(dropped)...)
+	messages = append(messages, prompt)
+
+	inferReq := InferenceRequest{
+		Model:        a.provider.Model,
+		SystemPrompt: "You are condensing part of a coding-agent's conversation history so it can be dropped from context.",
+		Messages:     messages,
+		MaxTokens:    512,
+		Stream:       false,
+	}
+
+	reqBody, err := provider.BuildRequest(inferReq)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", provider.RequestURL(a.provider, inferReq), strings.NewReader(string(reqBody)))
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range provider.AuthHeaders(a.provider.APIKey) {
+		httpReq.Header.Set(k, v)
+	}
+	for k, v := range a.provider.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ChatMessage{}, fmt.Errorf("summarization request failed: %s", string(body))
+	}
+
+	summary, err := provider.ParseResponse(body)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+
+	return ChatMessage{Role: "assistant", Content: "Conversation summary so far: " + summary.Content}, nil
+}
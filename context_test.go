@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func msg(id, role, content string) StoredMessage {
+	return StoredMessage{ID: id, Role: role, Content: content}
+}
+
+func TestGroupMessageTurnsKeepsToolPairsTogether(t *testing.T) {
+	history := []StoredMessage{
+		msg("1", "user", "hi"),
+		{ID: "2", Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1"}}},
+		msg("3", "tool", "result"),
+		msg("4", "user", "thanks"),
+	}
+
+	groups := groupMessageTurns(history)
+	if len(groups) != 3 {
+		t.Fatalf("got %d groups, want 3", len(groups))
+	}
+	if len(groups[1]) != 2 || groups[1][0].ID != "2" || groups[1][1].ID != "3" {
+		t.Errorf("tool-call/tool-result pair wasn't kept together: %+v", groups[1])
+	}
+}
+
+func TestFitContextWindowNoPruningNeeded(t *testing.T) {
+	a := &Agent{provider: &APIProvider{Model: "unknown-model"}, contextWindow: 100000}
+	history := []StoredMessage{msg("1", "user", "hello"), msg("2", "assistant", "hi there")}
+
+	got, err := a.fitContextWindow(context.Background(), history)
+	if err != nil {
+		t.Fatalf("fitContextWindow: %v", err)
+	}
+	if len(got) != len(history) {
+		t.Fatalf("got %d messages, want %d (nothing should be pruned)", len(got), len(history))
+	}
+}
+
+func TestFitContextWindowPrunesWithoutSummarizing(t *testing.T) {
+	// A large summarizeThreshold means even a big drop shouldn't trigger
+	// summarization, so this must never make an HTTP call.
+	a := &Agent{
+		provider:           &APIProvider{Model: "unknown-model"},
+		contextWindow:      50,
+		summarizeThreshold: 0.99,
+		client:             &http.Client{},
+	}
+
+	var history []StoredMessage
+	for i := 0; i < 20; i++ {
+		history = append(history, msg(string(rune('a'+i)), "user", strings.Repeat("x", 40)))
+	}
+
+	got, err := a.fitContextWindow(context.Background(), history)
+	if err != nil {
+		t.Fatalf("fitContextWindow: %v", err)
+	}
+	if len(got) >= len(history) {
+		t.Fatalf("got %d messages, want fewer than %d (oldest messages should be dropped)", len(got), len(history))
+	}
+}
+
+func TestDeltaSinceWithNoCache(t *testing.T) {
+	a := &Agent{}
+	dropped := []messageGroup{{msg("1", "user", "a")}, {msg("2", "user", "b")}}
+
+	toSummarize, carried := a.deltaSince(dropped)
+	if carried != nil {
+		t.Errorf("carried = %+v, want nil on an empty cache", carried)
+	}
+	if len(toSummarize) != 2 {
+		t.Errorf("got %d messages to summarize, want all 2", len(toSummarize))
+	}
+}
+
+func TestFitContextWindowSkipsSummarizeCallWhenNothingNewDropped(t *testing.T) {
+	cached := ChatMessage{Role: "assistant", Content: "cached summary"}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		t.Error("summarize should not make an HTTP call when the delta is empty")
+	}))
+	defer server.Close()
+
+	a := &Agent{
+		provider:           &APIProvider{Model: "unknown-model", ProviderType: "openai", BaseURL: server.URL},
+		contextWindow:      50,
+		summarizeThreshold: 0.1,
+		client:             &http.Client{},
+	}
+
+	bigLine := strings.Repeat("x", 40)
+	var history []StoredMessage
+	for i := 0; i < 15; i++ {
+		history = append(history, msg(string(rune('a'+i)), "user", bigLine))
+	}
+
+	groups := groupMessageTurns(history)
+	limit := contextWindowFor(a.provider.Model, a.contextWindow)
+	budget := limit - defaultMaxTokens
+	total := 0
+	for _, m := range history {
+		total += estimateTokens(ChatMessage{Content: m.Content, ToolCalls: m.ToolCalls})
+	}
+	keep := len(groups)
+	remaining := total
+	for keep > 1 && remaining > budget {
+		remaining -= groups[len(groups)-keep].tokens()
+		keep--
+	}
+	dropped := groups[:len(groups)-keep]
+
+	// Pretend a previous call already summarized exactly this dropped
+	// range, so this call's delta is empty.
+	a.summarizedThroughID = dropped[len(dropped)-1].lastID()
+	a.cachedSummary = cached
+
+	got, err := a.fitContextWindow(context.Background(), history)
+	if err != nil {
+		t.Fatalf("fitContextWindow: %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("got %d summarize HTTP calls, want 0", calls)
+	}
+	if got[0].Content != cached.Content {
+		t.Errorf("got synthetic summary %q, want the cached summary %q", got[0].Content, cached.Content)
+	}
+}
+
+func TestDeltaSinceReturnsOnlyNewGroupsPastCachedBoundary(t *testing.T) {
+	cached := ChatMessage{Role: "assistant", Content: "summary so far"}
+	a := &Agent{summarizedThroughID: "2", cachedSummary: cached}
+	dropped := []messageGroup{
+		{msg("1", "user", "a")},
+		{msg("2", "user", "b")},
+		{msg("3", "user", "c")},
+		{msg("4", "user", "d")},
+	}
+
+	toSummarize, carried := a.deltaSince(dropped)
+	if carried == nil || carried.Content != cached.Content {
+		t.Fatalf("carried = %+v, want the cached summary", carried)
+	}
+	if len(toSummarize) != 2 || toSummarize[0].ID != "3" || toSummarize[1].ID != "4" {
+		t.Errorf("got %+v, want only groups 3 and 4 (past the cached boundary)", toSummarize)
+	}
+}
+
+func TestDeltaSinceResetsWhenBoundaryMissing(t *testing.T) {
+	a := &Agent{summarizedThroughID: "stale-id", cachedSummary: ChatMessage{Content: "old"}}
+	dropped := []messageGroup{{msg("1", "user", "a")}, {msg("2", "user", "b")}}
+
+	toSummarize, carried := a.deltaSince(dropped)
+	if carried != nil {
+		t.Errorf("carried = %+v, want nil when the cached boundary isn't in dropped", carried)
+	}
+	if len(toSummarize) != 2 {
+		t.Errorf("got %d messages to summarize, want all 2 (cold re-summarize)", len(toSummarize))
+	}
+}
+
+// TestFitContextWindowCachesSummaryAcrossCalls drives fitContextWindow
+// through an actual summarize() HTTP round trip twice, on ever-growing
+// history, and asserts the second call's request only contains the newly
+// dropped delta (plus the carried-forward cached summary) instead of
+// re-summarizing the whole dropped prefix from scratch.
+func TestFitContextWindowCachesSummaryAcrossCalls(t *testing.T) {
+	var requests []openAICompatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAICompatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		requests = append(requests, req)
+
+		resp := openAICompatResponse{}
+		resp.Choices = make([]struct {
+			Message struct {
+				Content   string     `json:"content"`
+				ToolCalls []ToolCall `json:"tool_calls"`
+			} `json:"message"`
+		}, 1)
+		resp.Choices[0].Message.Content = "summary"
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	a := &Agent{
+		provider:           &APIProvider{Model: "unknown-model", ProviderType: "openai", BaseURL: server.URL},
+		contextWindow:      50,
+		summarizeThreshold: 0.1,
+		client:             &http.Client{},
+	}
+
+	bigLine := strings.Repeat("x", 40)
+	var history []StoredMessage
+	for i := 0; i < 15; i++ {
+		history = append(history, msg(string(rune('a'+i)), "user", bigLine))
+	}
+
+	if _, err := a.fitContextWindow(context.Background(), history); err != nil {
+		t.Fatalf("first fitContextWindow: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("got %d summarize requests after first call, want 1", len(requests))
+	}
+	firstRequestSize := len(requests[0].Messages)
+
+	for i := 0; i < 5; i++ {
+		history = append(history, msg(string(rune('p'+i)), "user", bigLine))
+	}
+
+	if _, err := a.fitContextWindow(context.Background(), history); err != nil {
+		t.Fatalf("second fitContextWindow: %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("got %d summarize requests after second call, want 2", len(requests))
+	}
+
+	secondRequestSize := len(requests[1].Messages)
+	if secondRequestSize >= firstRequestSize {
+		t.Errorf("second summarize request had %d messages, want fewer than the first's %d "+
+			"(only the new delta plus the carried summary should be sent, not the whole dropped prefix)",
+			secondRequestSize, firstRequestSize)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// StoredMessage is a single message in a conversation's history. Messages
+// form a tree via ParentID rather than a flat list: editing a prior user
+// message creates a new sibling message instead of mutating history, which
+// is how branching is represented.
+type StoredMessage struct {
+	ID             string
+	ConversationID string
+	ParentID       string // empty for the first message in a conversation
+	Role           string
+	Content        string
+	ToolCalls      []ToolCall
+	ToolCallID     string
+	CreatedAt      time.Time
+}
+
+// Conversation is a named thread of messages. HeadID points at the leaf
+// message of whichever branch is currently active; `branch` and editing a
+// message move it.
+type Conversation struct {
+	ID        string
+	Title     string
+	HeadID    string
+	CreatedAt time.Time
+}
+
+// ConversationStore persists conversations and their branching message
+// history. Agent.Run talks to it instead of holding conversation state in
+// an in-memory slice, so conversations survive across process runs.
+type ConversationStore interface {
+	CreateConversation(title string) (Conversation, error)
+	GetConversation(id string) (Conversation, error)
+	ListConversations() ([]Conversation, error)
+	DeleteConversation(id string) error
+	SetHead(conversationID, messageID string) error
+
+	// AppendMessage stores msg as a child of msg.ParentID, assigning an ID
+	// if msg.ID is empty, and returns the stored message.
+	AppendMessage(msg StoredMessage) (StoredMessage, error)
+
+	// History walks the parent chain from leafID back to the root and
+	// returns it in chronological (root-first) order. An empty leafID
+	// returns an empty history.
+	History(leafID string) ([]StoredMessage, error)
+
+	Close() error
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// toChatMessages converts stored history into the ChatMessage shape the
+// provider request builders expect.
+func toChatMessages(history []StoredMessage) []ChatMessage {
+	messages := make([]ChatMessage, 0, len(history))
+	for _, m := range history {
+		messages = append(messages, ChatMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			ToolCalls:  m.ToolCalls,
+			ToolCallID: m.ToolCallID,
+		})
+	}
+	return messages
+}
+
+// ErrMessageNotFound is returned when a message ID does not exist in the
+// store, e.g. when branching off a typo'd ID.
+func errMessageNotFound(id string) error {
+	return fmt.Errorf("message %q not found", id)
+}
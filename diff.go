@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+// unifiedDiff renders a minimal unified diff between before and after, in
+// the style `diff -u` and git produce, for use as a tool result so the
+// model (and a human reading the transcript) can see exactly what changed.
+func unifiedDiff(path string, before, after []string) string {
+	ops := diffLines(before, after)
+	hunks := groupIntoHunks(ops, diffContextLines)
+	if len(hunks) == 0 {
+		return fmt.Sprintf("--- a/%s\n+++ b/%s\n(no changes)\n", path, path)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	for _, span := range hunks {
+		writeHunk(&b, ops[span.start:span.end])
+	}
+	return b.String()
+}
+
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffDelete
+	diffInsert
+)
+
+type diffOp struct {
+	kind    diffOpKind
+	beforeI int // index into before, valid for equal/delete
+	afterI  int // index into after, valid for equal/insert
+	text    string
+}
+
+// maxLCSCells bounds the LCS DP table's (rows * cols) size. A real edit
+// (replace/insert_after_line/delete_range) only changes a small region of
+// the file, and the common-prefix/common-suffix trim in diffLines reduces
+// the table to just that region; this only bites for the rare case of a
+// near-total rewrite, where diffLinesNaive is used instead to keep memory
+// bounded (a 10,000-line file's full table alone would be ~800MB of ints).
+const maxLCSCells = 4_000_000
+
+// diffLines computes a line-level edit script turning before into after.
+// It first trims the common prefix and suffix so the expensive LCS DP
+// table only spans the region that actually differs, then falls back to a
+// non-minimal but linear-space diff if that region is still too large.
+func diffLines(before, after []string) []diffOp {
+	n, m := len(before), len(after)
+
+	prefix := 0
+	for prefix < n && prefix < m && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && before[n-1-suffix] == after[m-1-suffix] {
+		suffix++
+	}
+
+	midBefore, midAfter := before[prefix:n-suffix], after[prefix:m-suffix]
+
+	var ops []diffOp
+	for k := 0; k < prefix; k++ {
+		ops = append(ops, diffOp{kind: diffEqual, beforeI: k, afterI: k, text: before[k]})
+	}
+	if len(midBefore)*len(midAfter) > maxLCSCells {
+		ops = append(ops, diffLinesNaive(midBefore, midAfter, prefix)...)
+	} else {
+		ops = append(ops, lcsDiff(midBefore, midAfter, prefix)...)
+	}
+	for k := 0; k < suffix; k++ {
+		ops = append(ops, diffOp{kind: diffEqual, beforeI: n - suffix + k, afterI: m - suffix + k, text: before[n-suffix+k]})
+	}
+	return ops
+}
+
+// lcsDiff runs the standard LCS-based diff: an (len(before)+1) x
+// (len(after)+1) DP table, then a forward walk that emits equal/delete/
+// insert ops. offset is added to every beforeI/afterI so the result lines
+// up with indices in the caller's untrimmed before/after slices.
+func lcsDiff(before, after []string, offset int) []diffOp {
+	n, m := len(before), len(after)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case before[i] == after[j]:
+			ops = append(ops, diffOp{kind: diffEqual, beforeI: offset + i, afterI: offset + j, text: before[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: diffDelete, beforeI: offset + i, text: before[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: diffInsert, afterI: offset + j, text: after[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: diffDelete, beforeI: offset + i, text: before[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: diffInsert, afterI: offset + j, text: after[j]})
+	}
+	return ops
+}
+
+// diffLinesNaive emits every before line as deleted and every after line as
+// inserted, with no LCS alignment. It runs in linear space and time, for
+// use when the LCS table would otherwise be too large to allocate.
+func diffLinesNaive(before, after []string, offset int) []diffOp {
+	ops := make([]diffOp, 0, len(before)+len(after))
+	for i, line := range before {
+		ops = append(ops, diffOp{kind: diffDelete, beforeI: offset + i, text: line})
+	}
+	for j, line := range after {
+		ops = append(ops, diffOp{kind: diffInsert, afterI: offset + j, text: line})
+	}
+	return ops
+}
+
+// hunkSpan is a [start, end) range of indices into the ops slice.
+type hunkSpan struct {
+	start, end int
+}
+
+// groupIntoHunks splits the edit script into hunks, padding each run of
+// changes with up to `context` lines of surrounding equal lines and
+// merging hunks whose padding would otherwise overlap.
+func groupIntoHunks(ops []diffOp, context int) []hunkSpan {
+	var hunks []hunkSpan
+
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == diffEqual {
+			i++
+			continue
+		}
+
+		// Found the start of a change run; pad backwards.
+		start := i
+		for k := 0; k < context && start > 0 && ops[start-1].kind == diffEqual; k++ {
+			start--
+		}
+
+		// Consume the change run (and any equal lines shorter than
+		// 2*context that connect it to a following change run).
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != diffEqual {
+				end++
+				continue
+			}
+			// Look ahead: how many equal lines until the next change?
+			run := 0
+			for end+run < len(ops) && ops[end+run].kind == diffEqual {
+				run++
+			}
+			if end+run >= len(ops) || run > 2*context {
+				// Trailing context only, then stop.
+				if run > context {
+					run = context
+				}
+				end += run
+				break
+			}
+			end += run
+		}
+
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+		} else {
+			hunks = append(hunks, hunkSpan{start: start, end: end})
+		}
+		i = end
+	}
+
+	return hunks
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	var beforeStart, afterStart = -1, -1
+	var beforeCount, afterCount int
+
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			if beforeStart == -1 {
+				beforeStart, afterStart = op.beforeI, op.afterI
+			}
+			beforeCount++
+			afterCount++
+		case diffDelete:
+			if beforeStart == -1 {
+				beforeStart = op.beforeI
+			}
+			beforeCount++
+		case diffInsert:
+			if afterStart == -1 {
+				afterStart = op.afterI
+			}
+			afterCount++
+		}
+	}
+	if beforeStart == -1 {
+		beforeStart = 0
+	}
+	if afterStart == -1 {
+		afterStart = 0
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", beforeStart+1, beforeCount, afterStart+1, afterCount)
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			fmt.Fprintf(b, " %s\n", op.text)
+		case diffDelete:
+			fmt.Fprintf(b, "-%s\n", op.text)
+		case diffInsert:
+			fmt.Fprintf(b, "+%s\n", op.text)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffNoChanges(t *testing.T) {
+	lines := []string{"a", "b", "c"}
+	got := unifiedDiff("file.txt", lines, lines)
+	want := "--- a/file.txt\n+++ b/file.txt\n(no changes)\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestUnifiedDiffSingleLineChange(t *testing.T) {
+	before := []string{"one", "two", "three"}
+	after := []string{"one", "TWO", "three"}
+
+	got := unifiedDiff("file.txt", before, after)
+
+	want := "--- a/file.txt\n+++ b/file.txt\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" one\n" +
+		"-two\n" +
+		"+TWO\n" +
+		" three\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedDiffInsertAndDelete(t *testing.T) {
+	before := []string{"a", "b", "c"}
+	after := []string{"a", "c", "d"}
+
+	got := unifiedDiff("file.txt", before, after)
+
+	if !strings.Contains(got, "-b\n") {
+		t.Errorf("diff missing deleted line:\n%s", got)
+	}
+	if !strings.Contains(got, "+d\n") {
+		t.Errorf("diff missing inserted line:\n%s", got)
+	}
+}
+
+func TestDiffLinesTrimsCommonPrefixAndSuffixForLargeFiles(t *testing.T) {
+	const size = 5000
+	before := make([]string, size)
+	for i := range before {
+		before[i] = "line"
+	}
+	after := append([]string(nil), before...)
+	after[size/2] = "CHANGED"
+
+	ops := diffLines(before, after)
+
+	var deletes, inserts int
+	for _, op := range ops {
+		switch op.kind {
+		case diffDelete:
+			deletes++
+		case diffInsert:
+			inserts++
+		}
+	}
+	if deletes != 1 || inserts != 1 {
+		t.Fatalf("got %d deletes / %d inserts for a single changed line, want 1/1 (prefix/suffix trim isn't bounding the LCS table)", deletes, inserts)
+	}
+}
+
+func TestDiffLinesFallsBackToNaiveAboveLCSCellBound(t *testing.T) {
+	const size = 2100 // size*size > maxLCSCells
+	before := make([]string, size)
+	after := make([]string, size)
+	for i := range before {
+		before[i] = fmt.Sprintf("before-%d", i)
+		after[i] = fmt.Sprintf("after-%d", i)
+	}
+
+	ops := diffLines(before, after)
+
+	if len(ops) != len(before)+len(after) {
+		t.Fatalf("got %d ops, want %d (naive fallback should emit one op per line)", len(ops), len(before)+len(after))
+	}
+	for i, op := range ops {
+		if i < len(before) {
+			if op.kind != diffDelete || op.text != before[i] {
+				t.Fatalf("op %d = %+v, want delete %q", i, op, before[i])
+			}
+		} else {
+			j := i - len(before)
+			if op.kind != diffInsert || op.text != after[j] {
+				t.Fatalf("op %d = %+v, want insert %q", i, op, after[j])
+			}
+		}
+	}
+}
+
+func TestGroupIntoHunksSplitsDistantChanges(t *testing.T) {
+	before := make([]string, 0, 20)
+	after := make([]string, 0, 20)
+	for i := 0; i < 10; i++ {
+		before = append(before, "same")
+		after = append(after, "same")
+	}
+	before = append(before, "start-change")
+	after = append(after, "start-changed")
+	for i := 0; i < 10; i++ {
+		before = append(before, "same")
+		after = append(after, "same")
+	}
+	before = append(before, "end-change")
+	after = append(after, "end-changed")
+
+	ops := diffLines(before, after)
+	hunks := groupIntoHunks(ops, diffContextLines)
+
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2 (changes far enough apart shouldn't merge)", len(hunks))
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path"
@@ -16,58 +16,112 @@ import (
 	"github.com/invopop/jsonschema"
 )
 
+// APIProvider holds the resolved connection details (endpoint, key, model)
+// for whichever Provider implementation ProviderType names. The Provider
+// itself (in the providerRegistry) knows the wire format; APIProvider is
+// just the credentials/config to use it with.
 type APIProvider struct {
 	BaseURL      string
 	APIKey       string
 	Model        string
 	Headers      map[string]string
-	ProviderType string // "openrouter" or "anthropic"
+	ProviderType string // e.g. "openrouter", "anthropic", "ollama", "openai", "gemini"
 }
 
-func getAPIProvider() (*APIProvider, error) {
-	// Priority 1: OpenRouter
-	if apiKey := os.Getenv("OPENROUTER_API_KEY"); apiKey != "" {
-		return &APIProvider{
-			BaseURL:      "https://openrouter.ai/api/v1/chat/completions",
-			APIKey:       apiKey,
-			Model:        "anthropic/claude-sonnet-4.5",
-			ProviderType: "openrouter",
-			Headers: map[string]string{
-				"HTTP-Referer": "https://praktor.ai",
-				"X-Title":      "Praktor",
-			},
-		}, nil
+// getAPIProvider resolves providerType (see resolveProviderType) to a
+// Provider implementation and fills in its credentials from environment
+// variables: <PROVIDER>_API_KEY, <PROVIDER>_BASE_URL (optional override),
+// and PRAKTOR_MODEL (optional override of the provider's default model).
+func getAPIProvider(providerType string) (*APIProvider, error) {
+	provider, err := lookupProvider(providerType)
+	if err != nil {
+		return nil, err
 	}
 
-	// Priority 2: Anthropic API
-	apiKey := os.Getenv("ANTHROPIC_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("neither OPENROUTER_API_KEY nor ANTHROPIC_API_KEY environment variable is set")
+	envPrefix := strings.ToUpper(providerType)
+	apiKey := os.Getenv(envPrefix + "_API_KEY")
+	if apiKey == "" && providerType != "ollama" {
+		return nil, fmt.Errorf("%s_API_KEY environment variable is not set (selected provider: %s)", envPrefix, providerType)
 	}
 
-	baseURL := os.Getenv("ANTHROPIC_BASE_URL")
+	baseURL := os.Getenv(envPrefix + "_BASE_URL")
 	if baseURL == "" {
-		baseURL = "https://api.anthropic.com/v1/messages"
-	} else {
-		// Ensure custom base URL ends with /v1/messages
-		if !strings.HasSuffix(baseURL, "/v1/messages") {
-			baseURL = strings.TrimSuffix(baseURL, "/") + "/v1/messages"
-		}
+		baseURL = provider.DefaultEndpoint()
+	}
+
+	model := os.Getenv("PRAKTOR_MODEL")
+	if model == "" {
+		model = provider.DefaultModel()
 	}
 
 	return &APIProvider{
 		BaseURL:      baseURL,
 		APIKey:       apiKey,
-		Model:        "claude-sonnet-4-20250514",
-		ProviderType: "anthropic",
-		Headers: map[string]string{
-			"anthropic-version": "2023-06-01",
-		},
+		Model:        model,
+		Headers:      map[string]string{},
+		ProviderType: providerType,
 	}, nil
 }
 
 func main() {
-	provider, err := getAPIProvider()
+	if len(os.Args) > 1 {
+		if _, ok := conversationSubcommands[os.Args[1]]; ok {
+			if err := runConversationCommand(os.Args[1], os.Args[2:]); err != nil {
+				fmt.Printf("Error: %s\n", err.Error())
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	agentName := flag.String("agent", "coder", "name of the agent profile to run (see $XDG_CONFIG_HOME/praktor/agents.yaml)")
+	providerFlag := flag.String("provider", "", "backend to use: openrouter, anthropic, ollama, openai, or gemini (default: $PRAKTOR_PROVIDER, else sniffed from *_API_KEY env vars)")
+	yes := flag.Bool("yes", false, "skip confirmation prompts for mutating tool calls")
+	workspaceRootFlag := flag.String("workspace-root", ".", "directory tool calls are sandboxed to")
+	contextWindow := flag.Int("context-window", 0, "override the model's context window size in tokens (0 = use the built-in default for the model)")
+	summarizeThreshold := flag.Float64("summarize-threshold", defaultSummarizeThreshold, "summarize instead of silently dropping once pruning would discard this fraction of context")
+	allowTools := stringSetFlag{}
+	denyTools := stringSetFlag{}
+	flag.Var(&allowTools, "allow-tool", "tool name to pre-approve without prompting (repeatable)")
+	flag.Var(&denyTools, "deny-tool", "tool name to always refuse (repeatable)")
+	flag.Parse()
+
+	if err := setWorkspaceRoot(*workspaceRootFlag); err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	provider, err := getAPIProvider(resolveProviderType(*providerFlag))
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	profiles, err := loadAgentProfiles()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	profile, err := findAgentProfile(profiles, *agentName)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	storePath, err := defaultStorePath()
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+	store, err := OpenSQLiteStore(storePath)
+	if err != nil {
+		fmt.Printf("Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	conv, err := store.CreateConversation(fmt.Sprintf("REPL session (%s)", profile.Name))
 	if err != nil {
 		fmt.Printf("Error: %s\n", err.Error())
 		os.Exit(1)
@@ -81,44 +135,100 @@ func main() {
 		return scanner.Text(), true
 	}
 
-	tools := []ToolDefinition{
+	allTools := []ToolDefinition{
 		ReadFileDefinition,
 		ListFilesDefinition,
 		EditFileDefinition,
-	}
-
-	agent := NewAgent(provider, getUserMessage, tools)
+		ModifyFileDefinition,
+	}
+
+	agent := NewAgent(provider, getUserMessage, toolsForProfile(allTools, profile), AgentOptions{
+		SystemPrompt:       profile.SystemPrompt,
+		Store:              store,
+		ConversationID:     conv.ID,
+		AutoApprove:        *yes,
+		AllowTools:         allowTools.values,
+		DenyTools:          denyTools.values,
+		ContextWindow:      *contextWindow,
+		SummarizeThreshold: *summarizeThreshold,
+	})
 	err = agent.Run(context.TODO())
 	if err != nil {
 		fmt.Printf("Error: %s\n", err.Error())
 	}
 }
 
+// AgentOptions bundles the Agent constructor's less-central settings so
+// adding another one doesn't mean adding another positional parameter.
+type AgentOptions struct {
+	SystemPrompt       string
+	Store              ConversationStore
+	ConversationID     string
+	AutoApprove        bool            // --yes: skip confirmation for mutating tool calls
+	AllowTools         map[string]bool // --allow-tool: pre-approved, never prompted
+	DenyTools          map[string]bool // --deny-tool: always refused, never run
+	ContextWindow      int             // --context-window: overrides the model's default window size in tokens (0 = use the default)
+	SummarizeThreshold float64         // --summarize-threshold: summarize instead of drop once this fraction of context would be pruned
+}
+
 func NewAgent(
 	provider *APIProvider,
 	getUserMessage func() (string, bool),
 	tools []ToolDefinition,
+	opts AgentOptions,
 ) *Agent {
+	summarizeThreshold := opts.SummarizeThreshold
+	if summarizeThreshold == 0 {
+		summarizeThreshold = defaultSummarizeThreshold
+	}
+
 	return &Agent{
-		provider:       provider,
-		getUserMessage: getUserMessage,
-		tools:          tools,
-		client:         &http.Client{},
+		provider:           provider,
+		getUserMessage:     getUserMessage,
+		tools:              tools,
+		systemPrompt:       opts.SystemPrompt,
+		store:              opts.Store,
+		conversationID:     opts.ConversationID,
+		autoApprove:        opts.AutoApprove,
+		allowTools:         opts.AllowTools,
+		denyTools:          opts.DenyTools,
+		sessionApproved:    map[string]bool{},
+		contextWindow:      opts.ContextWindow,
+		summarizeThreshold: summarizeThreshold,
+		client:             &http.Client{},
 	}
 }
 
 type Agent struct {
-	provider       *APIProvider
-	getUserMessage func() (string, bool)
-	tools          []ToolDefinition
-	client         *http.Client
+	provider           *APIProvider
+	getUserMessage     func() (string, bool)
+	tools              []ToolDefinition
+	systemPrompt       string
+	store              ConversationStore
+	conversationID     string
+	headID             string
+	autoApprove        bool
+	allowTools         map[string]bool
+	denyTools          map[string]bool
+	sessionApproved    map[string]bool
+	contextWindow      int
+	summarizeThreshold float64
+	client             *http.Client
+
+	// summarizedThroughID and cachedSummary cache the result of the last
+	// fitContextWindow summarization: summarizedThroughID is the StoredMessage
+	// ID of the newest message folded into cachedSummary. As history grows,
+	// only the newly-dropped delta past this ID needs summarizing, instead of
+	// re-summarizing the whole ever-growing dropped prefix on every turn.
+	summarizedThroughID string
+	cachedSummary       ChatMessage
 }
 
 type ChatMessage struct {
-	Role       string      `json:"role"`
-	Content    string      `json:"content"`
-	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
-	ToolCallID string      `json:"tool_call_id,omitempty"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
 
 type ToolCall struct {
@@ -130,46 +240,8 @@ type ToolCall struct {
 	} `json:"function"`
 }
 
-type ChatResponse struct {
-	ID      string `json:"id"`
-	Choices []struct {
-		Message struct {
-			Role      string     `json:"role"`
-			Content   string     `json:"content"`
-			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
-		} `json:"message"`
-	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-		Code    interface{} `json:"code"`
-	} `json:"error,omitempty"`
-}
-
-type ChatRequest struct {
-	Model     string        `json:"model"`
-	Messages  []ChatMessage `json:"messages"`
-	Tools     []ToolDef     `json:"tools,omitempty"`
-	MaxTokens int           `json:"max_tokens,omitempty"`
-}
-
-type ToolDef struct {
-	Type     string `json:"type"`
-	Function struct {
-		Name        string                 `json:"name"`
-		Description string                 `json:"description"`
-		Parameters  map[string]interface{} `json:"parameters"`
-	} `json:"function"`
-}
-
 func (a *Agent) Run(ctx context.Context) error {
-	conversation := []ChatMessage{}
-
-	apiName := "OpenRouter"
-	if a.provider.ProviderType == "anthropic" {
-		apiName = "Anthropic"
-	}
-	fmt.Printf("Chat with Praktor powered by %s (use 'ctrl-c' to quit)\n", apiName)
+	fmt.Printf("Chat with Praktor powered by %s/%s (use 'ctrl-c' to quit)\n", a.provider.ProviderType, a.provider.Model)
 
 	readUserInput := true
 	for {
@@ -180,299 +252,132 @@ func (a *Agent) Run(ctx context.Context) error {
 				break
 			}
 
-			conversation = append(conversation, ChatMessage{
-				Role:    "user",
-				Content: userInput,
-			})
+			if err := a.appendTurn("user", userInput, nil, ""); err != nil {
+				return err
+			}
 		}
 
-		toolCalls, responseText, err := a.runInference(ctx, conversation)
+		fmt.Print("\u001b[93mPraktor\u001b[0m: ")
+		_, lastRole, err := a.step(ctx, true)
 		if err != nil {
 			return err
 		}
 
-		if responseText != "" {
-			fmt.Printf("\u001b[93mPraktor\u001b[0m: %s\n", responseText)
-			conversation = append(conversation, ChatMessage{
-				Role:    "assistant",
-				Content: responseText,
-			})
-		}
-
-		if len(toolCalls) == 0 {
+		if lastRole != "tool" {
 			readUserInput = true
 			continue
 		}
 
-		// Add assistant message with tool calls to conversation
-		asstMsgBytes, _ := json.Marshal(struct {
-			Role      string     `json:"role"`
-			Content   string     `json:"content"`
-			ToolCalls []ToolCall `json:"tool_calls"`
-		}{
-			Role:      "assistant",
-			Content:   responseText,
-			ToolCalls: toolCalls,
-		})
-		var asstMsgParsed ChatMessage
-		json.Unmarshal(asstMsgBytes, &asstMsgParsed)
-		conversation = append(conversation, asstMsgParsed)
-
-		// Execute tools
-		for _, toolCall := range toolCalls {
-			result := a.executeTool(toolCall.ID, toolCall.Function.Name, toolCall.Function.Arguments)
-			conversation = append(conversation, ChatMessage{
-				Role:       "tool",
-				Content:    result,
-				ToolCallID: toolCall.ID,
-			})
-		}
-
 		readUserInput = false
 	}
 
 	return nil
 }
 
-func (a *Agent) runInference(ctx context.Context, conversation []ChatMessage) ([]ToolCall, string, error) {
-	isAnthropic := a.provider.ProviderType == "anthropic"
-
-	var reqBody []byte
-	var err error
-
-	if isAnthropic {
-		// Anthropic format
-		reqBody, err = a.buildAnthropicRequest(conversation)
-	} else {
-		// OpenRouter/OpenAI format
-		reqBody, err = a.buildOpenRouterRequest(conversation)
-	}
-
-	if err != nil {
-		return nil, "", err
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.provider.BaseURL, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, "", err
+// Reply runs a single user turn to completion (including any tool-call
+// round trips) non-interactively, and returns the assistant's final text.
+// It backs the `praktor reply` subcommand.
+func (a *Agent) Reply(ctx context.Context, userInput string) (string, error) {
+	if err := a.appendTurn("user", userInput, nil, ""); err != nil {
+		return "", err
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	if isAnthropic {
-		httpReq.Header.Set("x-api-key", a.provider.APIKey)
-		for k, v := range a.provider.Headers {
-			httpReq.Header.Set(k, v)
+	for {
+		responseText, lastRole, err := a.step(ctx, false)
+		if err != nil {
+			return "", err
 		}
-	} else {
-		httpReq.Header.Set("Authorization", "Bearer "+a.provider.APIKey)
-		for k, v := range a.provider.Headers {
-			httpReq.Header.Set(k, v)
+		if lastRole != "tool" {
+			return responseText, nil
 		}
 	}
+}
 
-	resp, err := a.client.Do(httpReq)
+// step streams inference against the current history, printing text
+// deltas to stdout as they arrive when live is true, appends the
+// resulting assistant message (and any tool results) to the store, and
+// returns the assistant's text along with the role of the last message
+// appended (so callers know whether another inference round trip is
+// needed).
+func (a *Agent) step(ctx context.Context, live bool) (string, string, error) {
+	history, err := a.store.History(a.headID)
 	if err != nil {
-		return nil, "", err
+		return "", "", err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	conversation, err := a.fitContextWindow(ctx, history)
 	if err != nil {
-		return nil, "", err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("API error: %s", string(body))
-	}
-
-	if isAnthropic {
-		return a.parseAnthropicResponse(body)
-	}
-	return a.parseOpenRouterResponse(body)
-}
-
-func (a *Agent) buildOpenRouterRequest(conversation []ChatMessage) ([]byte, error) {
-	tools := []ToolDef{}
-	for _, tool := range a.tools {
-		params := map[string]interface{}{
-			"type":       "object",
-			"properties": tool.InputSchema.Properties,
-		}
-		td := ToolDef{
-			Type: "function",
-		}
-		td.Function.Name = tool.Name
-		td.Function.Description = tool.Description
-		td.Function.Parameters = params
-		tools = append(tools, td)
+		return "", "", err
 	}
 
-	req := ChatRequest{
-		Model:     a.provider.Model,
-		Messages:  conversation,
-		Tools:     tools,
-		MaxTokens: 4096,
+	chunks, err := a.streamInference(ctx, conversation)
+	if err != nil {
+		return "", "", err
 	}
 
-	return json.Marshal(req)
-}
-
-func (a *Agent) buildAnthropicRequest(conversation []ChatMessage) ([]byte, error) {
-	// Anthropic uses a different message format
-	type AnthropicMessage struct {
-		Role    string `json:"role"`
-		Content any    `json:"content"`
-	}
-
-	type AnthropicToolDef struct {
-		Name        string                 `json:"name"`
-		Description string                 `json:"description"`
-		InputSchema map[string]interface{} `json:"input_schema"`
-	}
-
-	type AnthropicRequest struct {
-		Model     string               `json:"model"`
-		Messages  []AnthropicMessage   `json:"messages"`
-		Tools     []AnthropicToolDef   `json:"tools,omitempty"`
-		MaxTokens int                  `json:"max_tokens,omitempty"`
-	}
-
-	messages := []AnthropicMessage{}
-	for _, msg := range conversation {
-		if msg.Role == "tool" {
-			// Anthropic uses "user" role for tool responses with specific format
-			messages = append(messages, AnthropicMessage{
-				Role: "user",
-				Content: map[string]interface{}{
-					"type":      "tool_result",
-					"tool_use_id": msg.ToolCallID,
-					"content":   msg.Content,
-				},
-			})
-		} else if len(msg.ToolCalls) > 0 {
-			// Assistant message with tool calls
-			blocks := []map[string]interface{}{
-				{"type": "text", "text": msg.Content},
-			}
-			for _, tc := range msg.ToolCalls {
-				var args map[string]interface{}
-				json.Unmarshal([]byte(tc.Function.Arguments), &args)
-				blocks = append(blocks, map[string]interface{}{
-					"type":        "tool_use",
-					"id":          tc.ID,
-					"name":        tc.Function.Name,
-					"input":       args,
-				})
+	assembler := newToolCallAssembler()
+	var text strings.Builder
+	for chunk := range chunks {
+		switch chunk.Type {
+		case TextDelta:
+			text.WriteString(chunk.Text)
+			if live {
+				fmt.Print(chunk.Text)
 			}
-			messages = append(messages, AnthropicMessage{
-				Role:    "assistant",
-				Content: blocks,
-			})
-		} else {
-			messages = append(messages, AnthropicMessage{
-				Role:    msg.Role,
-				Content: msg.Content,
-			})
+		case ToolCallStart, ToolCallArgDelta, ToolCallEnd:
+			assembler.handle(chunk)
 		}
 	}
-
-	tools := []AnthropicToolDef{}
-	for _, tool := range a.tools {
-		td := AnthropicToolDef{
-			Name:        tool.Name,
-			Description: tool.Description,
-			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": tool.InputSchema.Properties,
-			},
-		}
-		tools = append(tools, td)
-	}
-
-	req := AnthropicRequest{
-		Model:     a.provider.Model,
-		Messages:  messages,
-		Tools:     tools,
-		MaxTokens: 4096,
+	if live && text.Len() > 0 {
+		fmt.Println()
 	}
 
-	return json.Marshal(req)
-}
-
-func (a *Agent) parseOpenRouterResponse(body []byte) ([]ToolCall, string, error) {
-	var response ChatResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, "", err
-	}
+	responseText := text.String()
+	toolCalls := assembler.finish()
 
-	if response.Error != nil {
-		return nil, "", fmt.Errorf("API error: %s", response.Error.Message)
+	if err := a.appendTurn("assistant", responseText, toolCalls, ""); err != nil {
+		return "", "", err
 	}
 
-	if len(response.Choices) == 0 {
-		return nil, "", fmt.Errorf("no choices in response")
+	if len(toolCalls) == 0 {
+		return responseText, "assistant", nil
 	}
 
-	choice := response.Choices[0]
-	var toolCalls []ToolCall
-	if len(choice.Message.ToolCalls) > 0 {
-		toolCalls = choice.Message.ToolCalls
+	for _, toolCall := range toolCalls {
+		result := a.executeTool(toolCall.ID, toolCall.Function.Name, toolCall.Function.Arguments)
+		if err := a.appendTurn("tool", result, nil, toolCall.ID); err != nil {
+			return "", "", err
+		}
 	}
 
-	return toolCalls, choice.Message.Content, nil
+	return responseText, "tool", nil
 }
 
-func (a *Agent) parseAnthropicResponse(body []byte) ([]ToolCall, string, error) {
-	type AnthropicContentBlock struct {
-		Type string `json:"type"`
-		Text string `json:"text,omitempty"`
-		ID   string `json:"id,omitempty"`
-		Name string `json:"name,omitempty"`
-		Input map[string]interface{} `json:"input,omitempty"`
-	}
-
-	type AnthropicResponse struct {
-		ID      string `json:"id"`
-		Content []AnthropicContentBlock `json:"content"`
-		Error   *struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-		} `json:"error,omitempty"`
-	}
-
-	var response AnthropicResponse
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, "", err
-	}
-
-	if response.Error != nil {
-		return nil, "", fmt.Errorf("API error: %s", response.Error.Message)
-	}
-
-	var toolCalls []ToolCall
-	var textContent strings.Builder
-
-	for _, block := range response.Content {
-		if block.Type == "text" {
-			textContent.WriteString(block.Text)
-		} else if block.Type == "tool_use" {
-			args, _ := json.Marshal(block.Input)
-			toolCalls = append(toolCalls, ToolCall{
-				ID:   block.ID,
-				Type: "function",
-				Function: struct {
-					Name      string `json:"name"`
-					Arguments string `json:"arguments"`
-				}{
-					Name:      block.Name,
-					Arguments: string(args),
-				},
-			})
-		}
+// appendTurn stores a message as a child of the current head and advances
+// the head to it.
+func (a *Agent) appendTurn(role, content string, toolCalls []ToolCall, toolCallID string) error {
+	msg, err := a.store.AppendMessage(StoredMessage{
+		ConversationID: a.conversationID,
+		ParentID:       a.headID,
+		Role:           role,
+		Content:        content,
+		ToolCalls:      toolCalls,
+		ToolCallID:     toolCallID,
+	})
+	if err != nil {
+		return err
 	}
+	a.headID = msg.ID
+	return nil
+}
 
-	return toolCalls, textContent.String(), nil
+// mutatingTools lists tool names that can change the filesystem (or, for
+// the not-yet-implemented run_command, arbitrary state) and therefore go
+// through the confirmation gate in executeTool by default.
+var mutatingTools = map[string]bool{
+	"edit_file":   true,
+	"modify_file": true,
+	"run_command": true,
 }
 
 func (a *Agent) executeTool(id, name string, arguments string) string {
@@ -489,6 +394,20 @@ func (a *Agent) executeTool(id, name string, arguments string) string {
 		return fmt.Sprintf("Error: tool not found")
 	}
 
+	if a.denyTools[name] {
+		return fmt.Sprintf("Error: tool %q is denied by policy", name)
+	}
+
+	if mutatingTools[name] && !a.autoApprove && !a.allowTools[name] && !a.sessionApproved[name] {
+		approved, err := a.confirmToolCall(name, arguments)
+		if err != nil {
+			return fmt.Sprintf("Error: %s", err.Error())
+		}
+		if !approved {
+			return fmt.Sprintf("Error: tool call %s(%s) was not approved", name, arguments)
+		}
+	}
+
 	fmt.Printf("\u001b[92mtool\u001b[0m: %s(%s)\n", name, arguments)
 	response, err := toolDef.Function([]byte(arguments))
 	if err != nil {
@@ -497,6 +416,33 @@ func (a *Agent) executeTool(id, name string, arguments string) string {
 	return response
 }
 
+// confirmToolCall prompts the user to approve a mutating tool call with
+// [y/N/always]. "always" pre-approves every future call to this tool name
+// for the rest of the session. Without an interactive input source (e.g.
+// the scripted `praktor reply` path without --yes) it safely defaults to
+// denying the call.
+func (a *Agent) confirmToolCall(name, arguments string) (bool, error) {
+	if a.getUserMessage == nil {
+		return false, nil
+	}
+
+	fmt.Printf("\u001b[91mconfirm\u001b[0m: run %s(%s)? [y/N/always] ", name, arguments)
+	answer, ok := a.getUserMessage()
+	if !ok {
+		return false, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	case "always":
+		a.sessionApproved[name] = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 type ToolDefinition struct {
 	Name        string
 	Description string
@@ -528,7 +474,12 @@ func ReadFile(input []byte) (string, error) {
 		panic(err)
 	}
 
-	content, err := os.ReadFile(readFileInput.Path)
+	resolved, err := resolveWorkspacePath(readFileInput.Path)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(resolved)
 	if err != nil {
 		return "", err
 	}
@@ -563,13 +514,18 @@ func ListFiles(input []byte) (string, error) {
 		dir = listFilesInput.Path
 	}
 
+	resolvedDir, err := resolveWorkspacePath(dir)
+	if err != nil {
+		return "", err
+	}
+
 	var files []string
-	walkErr := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	walkErr := filepath.Walk(resolvedDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		relPath, err := filepath.Rel(dir, path)
+		relPath, err := filepath.Rel(resolvedDir, path)
 		if err != nil {
 			return err
 		}
@@ -627,10 +583,15 @@ func EditFile(input []byte) (string, error) {
 		return "", fmt.Errorf("invalid input parameters")
 	}
 
-	content, err := os.ReadFile(editFileInput.Path)
+	resolved, err := resolveWorkspacePath(editFileInput.Path)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(resolved)
 	if err != nil {
 		if os.IsNotExist(err) && editFileInput.OldStr == "" {
-			return createNewFile(editFileInput.Path, editFileInput.NewStr)
+			return createNewFile(resolved, editFileInput.NewStr)
 		}
 		return "", err
 	}
@@ -642,7 +603,7 @@ func EditFile(input []byte) (string, error) {
 		return "", fmt.Errorf("old_str not found in file")
 	}
 
-	err = os.WriteFile(editFileInput.Path, []byte(newContent), 0644)
+	err = os.WriteFile(resolved, []byte(newContent), 0644)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// InferenceRequest is the provider-agnostic shape of everything a Provider
+// needs to build a wire request. Agent assembles one of these from its own
+// state (system prompt, tool registry, conversation history) so individual
+// Provider implementations never need to know about Agent.
+type InferenceRequest struct {
+	Model        string
+	SystemPrompt string
+	Messages     []ChatMessage
+	Tools        []ToolDefinition
+	MaxTokens    int
+	Stream       bool
+}
+
+// Provider knows how to talk one backend's wire format: how to build a
+// request body, how to parse a non-streaming response, how to turn a
+// streaming response body into Chunks, and what auth headers it needs.
+// Agent.streamInference is the only caller; everything provider-specific
+// (endpoint shape, tool-call encoding, SSE framing) lives behind this
+// interface instead of an if/else ladder keyed on ProviderType.
+type Provider interface {
+	// Name is the ProviderType string this Provider answers to, e.g.
+	// "openrouter", "anthropic", "ollama", "openai", "gemini".
+	Name() string
+
+	// DefaultEndpoint is used when the user hasn't overridden the base URL
+	// via the provider's *_BASE_URL environment variable.
+	DefaultEndpoint() string
+
+	// DefaultModel is used when the user hasn't set PRAKTOR_MODEL.
+	DefaultModel() string
+
+	// RequestURL returns the full URL to POST req to, given the provider's
+	// resolved BaseURL/Model/APIKey. Most providers just return p.BaseURL
+	// unchanged; Gemini encodes the model, API key, and streaming mode
+	// (generateContent vs streamGenerateContent?alt=sse) into the URL
+	// itself, so it needs req.Stream to pick the right one.
+	RequestURL(p *APIProvider, req InferenceRequest) string
+
+	// AuthHeaders returns the headers needed to authenticate apiKey with
+	// this provider (e.g. "Authorization: Bearer ..." or "x-api-key: ...").
+	AuthHeaders(apiKey string) map[string]string
+
+	// BuildRequest marshals an InferenceRequest into this provider's wire
+	// format.
+	BuildRequest(req InferenceRequest) ([]byte, error)
+
+	// ParseResponse parses a complete (non-streaming) response body into a
+	// single assistant ChatMessage.
+	ParseResponse(body []byte) (ChatMessage, error)
+
+	// StreamResponse reads a streaming response body and emits Chunks to ch
+	// as they arrive. It does not send the final Done chunk or close ch;
+	// the caller (Agent.streamInference) does both once StreamResponse
+	// returns.
+	StreamResponse(body io.Reader, ch chan<- Chunk)
+}
+
+// providerRegistry lists every known Provider, keyed by the name used in
+// PRAKTOR_PROVIDER, --provider, and APIProvider.ProviderType.
+var providerRegistry = map[string]Provider{
+	"openrouter": OpenRouterProvider{},
+	"anthropic":  AnthropicProvider{},
+	"ollama":     OllamaProvider{},
+	"openai":     OpenAIProvider{},
+	"gemini":     GeminiProvider{},
+}
+
+// lookupProvider resolves a ProviderType string to its Provider
+// implementation.
+func lookupProvider(name string) (Provider, error) {
+	p, ok := providerRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (known providers: openrouter, anthropic, ollama, openai, gemini)", name)
+	}
+	return p, nil
+}
+
+// resolveProviderType decides which provider to use, in priority order:
+// the --provider flag, the PRAKTOR_PROVIDER env var, then the legacy
+// API-key sniffing this CLI has always done (OpenRouter, then Anthropic)
+// so existing setups keep working unconfigured.
+func resolveProviderType(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv("PRAKTOR_PROVIDER"); env != "" {
+		return env
+	}
+	if os.Getenv("OPENROUTER_API_KEY") != "" {
+		return "openrouter"
+	}
+	if os.Getenv("ANTHROPIC_API_KEY") != "" {
+		return "anthropic"
+	}
+	if os.Getenv("OPENAI_API_KEY") != "" {
+		return "openai"
+	}
+	if os.Getenv("GEMINI_API_KEY") != "" {
+		return "gemini"
+	}
+	return "ollama"
+}
+
+// toolDefsFor builds the provider-agnostic schema blob shared by every
+// OpenAI-compatible provider (OpenRouter, OpenAI itself, Ollama).
+func toolParametersFor(tool ToolDefinition) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": tool.InputSchema.Properties,
+	}
+}
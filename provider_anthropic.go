@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// AnthropicProvider talks to Anthropic's native /v1/messages endpoint,
+// whose request/response shape differs from the OpenAI family enough
+// (top-level `system`, content-block messages, `x-api-key` auth, its own
+// SSE event framing) to warrant its own implementation rather than
+// sharing the openai-compat helpers.
+type AnthropicProvider struct{}
+
+func (AnthropicProvider) Name() string            { return "anthropic" }
+func (AnthropicProvider) DefaultEndpoint() string { return "https://api.anthropic.com/v1/messages" }
+func (AnthropicProvider) DefaultModel() string    { return "claude-sonnet-4-20250514" }
+
+func (AnthropicProvider) AuthHeaders(apiKey string) map[string]string {
+	return map[string]string{
+		"x-api-key":         apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+}
+
+func (AnthropicProvider) RequestURL(p *APIProvider, req InferenceRequest) string {
+	return p.BaseURL
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type anthropicToolDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicToolDef `json:"tools,omitempty"`
+	MaxTokens int                `json:"max_tokens,omitempty"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+func (AnthropicProvider) BuildRequest(req InferenceRequest) ([]byte, error) {
+	messages := []anthropicMessage{}
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "tool":
+			messages = append(messages, anthropicMessage{
+				Role: "user",
+				Content: map[string]interface{}{
+					"type":        "tool_result",
+					"tool_use_id": msg.ToolCallID,
+					"content":     msg.Content,
+				},
+			})
+		case len(msg.ToolCalls) > 0:
+			blocks := []map[string]interface{}{
+				{"type": "text", "text": msg.Content},
+			}
+			for _, tc := range msg.ToolCalls {
+				var args map[string]interface{}
+				json.Unmarshal([]byte(tc.Function.Arguments), &args)
+				blocks = append(blocks, map[string]interface{}{
+					"type":  "tool_use",
+					"id":    tc.ID,
+					"name":  tc.Function.Name,
+					"input": args,
+				})
+			}
+			messages = append(messages, anthropicMessage{Role: "assistant", Content: blocks})
+		default:
+			messages = append(messages, anthropicMessage{Role: msg.Role, Content: msg.Content})
+		}
+	}
+
+	tools := []anthropicToolDef{}
+	for _, tool := range req.Tools {
+		tools = append(tools, anthropicToolDef{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: toolParametersFor(tool),
+		})
+	}
+
+	return json.Marshal(anthropicRequest{
+		Model:     req.Model,
+		System:    req.SystemPrompt,
+		Messages:  messages,
+		Tools:     tools,
+		MaxTokens: req.MaxTokens,
+		Stream:    req.Stream,
+	})
+}
+
+func (AnthropicProvider) ParseResponse(body []byte) (ChatMessage, error) {
+	var parsed struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			ID    string          `json:"id"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ChatMessage{}, err
+	}
+
+	msg := ChatMessage{Role: "assistant"}
+	for _, block := range parsed.Content {
+		switch block.Type {
+		case "text":
+			msg.Content += block.Text
+		case "tool_use":
+			tc := ToolCall{ID: block.ID, Type: "function"}
+			tc.Function.Name = block.Name
+			tc.Function.Arguments = string(block.Input)
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+	}
+	return msg, nil
+}
+
+// StreamResponse parses Anthropic's SSE event stream: message_start,
+// content_block_start, content_block_delta (text_delta / input_json_delta),
+// content_block_stop, message_delta, message_stop. input_json_delta
+// fragments are buffered per content-block index and only treated as a
+// complete tool call once content_block_stop arrives for that index.
+func (AnthropicProvider) StreamResponse(body io.Reader, ch chan<- Chunk) {
+	type contentBlock struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	type eventPayload struct {
+		Index        int          `json:"index"`
+		ContentBlock contentBlock `json:"content_block"`
+		Delta        struct {
+			Type        string `json:"type"`
+			Text        string `json:"text"`
+			PartialJSON string `json:"partial_json"`
+		} `json:"delta"`
+	}
+
+	partialArgs := map[int]*strings.Builder{}
+	toolMeta := map[int]ToolCall{}
+
+	scanner := bufio.NewScanner(bufio.NewReader(body))
+	var event string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if data == "" {
+				continue
+			}
+
+			var payload eventPayload
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				continue
+			}
+
+			switch event {
+			case "content_block_start":
+				if payload.ContentBlock.Type == "tool_use" {
+					toolMeta[payload.Index] = ToolCall{
+						ID:   payload.ContentBlock.ID,
+						Type: "function",
+						Function: struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						}{Name: payload.ContentBlock.Name},
+					}
+					partialArgs[payload.Index] = &strings.Builder{}
+					ch <- Chunk{Type: ToolCallStart, Index: payload.Index, ToolCall: toolMeta[payload.Index]}
+				}
+			case "content_block_delta":
+				switch payload.Delta.Type {
+				case "text_delta":
+					ch <- Chunk{Type: TextDelta, Text: payload.Delta.Text}
+				case "input_json_delta":
+					if b, ok := partialArgs[payload.Index]; ok {
+						b.WriteString(payload.Delta.PartialJSON)
+						ch <- Chunk{Type: ToolCallArgDelta, Index: payload.Index, ArgsDelta: payload.Delta.PartialJSON}
+					}
+				}
+			case "content_block_stop":
+				if b, ok := partialArgs[payload.Index]; ok {
+					tc := toolMeta[payload.Index]
+					tc.Function.Arguments = b.String()
+					ch <- Chunk{Type: ToolCallEnd, Index: payload.Index, ToolCall: tc}
+					delete(partialArgs, payload.Index)
+				}
+			case "message_stop":
+				return
+			}
+		}
+	}
+}
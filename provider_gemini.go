@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GeminiProvider talks to Google's generateContent API. Unlike the other
+// providers, the API key travels as a `key` query parameter baked into the
+// endpoint URL (see RequestURL) rather than a header, so AuthHeaders
+// returns nothing.
+type GeminiProvider struct{}
+
+func (GeminiProvider) Name() string         { return "gemini" }
+func (GeminiProvider) DefaultModel() string { return "gemini-2.0-flash" }
+func (GeminiProvider) DefaultEndpoint() string {
+	return "https://generativelanguage.googleapis.com/v1beta"
+}
+
+func (GeminiProvider) AuthHeaders(apiKey string) map[string]string {
+	return map[string]string{}
+}
+
+// RequestURL picks Gemini's action based on req.Stream: a streaming call
+// hits streamGenerateContent with alt=sse (the format StreamResponse
+// parses), while a non-streaming call (e.g. context-window summarization)
+// hits plain generateContent, which returns one JSON object instead of an
+// SSE body ParseResponse can decode directly.
+func (GeminiProvider) RequestURL(p *APIProvider, req InferenceRequest) string {
+	action := "generateContent"
+	if req.Stream {
+		action = "streamGenerateContent"
+	}
+	url := fmt.Sprintf("%s/models/%s:%s?key=%s", p.BaseURL, p.Model, action, p.APIKey)
+	if req.Stream {
+		url += "&alt=sse"
+	}
+	return url
+}
+
+type geminiPart struct {
+	Text         string              `json:"text,omitempty"`
+	FunctionCall *geminiFunctionCall `json:"functionCall,omitempty"`
+	FunctionResp *geminiFunctionResp `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
+}
+
+type geminiFunctionResp struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiFunctionDecl struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDecl `json:"functionDeclarations"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"system_instruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+	Tools             []geminiTool    `json:"tools,omitempty"`
+}
+
+// BuildRequest maps the shared ChatMessage history onto Gemini's
+// content/parts shape: assistant tool calls become `functionCall` parts on
+// a "model" content, and tool results become `functionResponse` parts on a
+// "function" content, keeping the tool_call_id -> name association that
+// Gemini needs by name rather than ID.
+func (GeminiProvider) BuildRequest(req InferenceRequest) ([]byte, error) {
+	callNameByID := map[string]string{}
+	for _, msg := range req.Messages {
+		for _, tc := range msg.ToolCalls {
+			callNameByID[tc.ID] = tc.Function.Name
+		}
+	}
+
+	var contents []geminiContent
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "tool":
+			var response map[string]interface{}
+			if err := json.Unmarshal([]byte(msg.Content), &response); err != nil {
+				response = map[string]interface{}{"result": msg.Content}
+			}
+			contents = append(contents, geminiContent{
+				Role: "function",
+				Parts: []geminiPart{{
+					FunctionResp: &geminiFunctionResp{Name: callNameByID[msg.ToolCallID], Response: response},
+				}},
+			})
+		case len(msg.ToolCalls) > 0:
+			parts := []geminiPart{}
+			if msg.Content != "" {
+				parts = append(parts, geminiPart{Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				var args map[string]interface{}
+				json.Unmarshal([]byte(tc.Function.Arguments), &args)
+				parts = append(parts, geminiPart{FunctionCall: &geminiFunctionCall{Name: tc.Function.Name, Args: args}})
+			}
+			contents = append(contents, geminiContent{Role: "model", Parts: parts})
+		default:
+			role := "user"
+			if msg.Role == "assistant" {
+				role = "model"
+			}
+			contents = append(contents, geminiContent{Role: role, Parts: []geminiPart{{Text: msg.Content}}})
+		}
+	}
+
+	var tools []geminiTool
+	if len(req.Tools) > 0 {
+		decls := make([]geminiFunctionDecl, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			decls = append(decls, geminiFunctionDecl{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  toolParametersFor(tool),
+			})
+		}
+		tools = append(tools, geminiTool{FunctionDeclarations: decls})
+	}
+
+	out := geminiRequest{Contents: contents, Tools: tools}
+	if req.SystemPrompt != "" {
+		out.SystemInstruction = &geminiContent{Parts: []geminiPart{{Text: req.SystemPrompt}}}
+	}
+	return json.Marshal(out)
+}
+
+func (GeminiProvider) ParseResponse(body []byte) (ChatMessage, error) {
+	var parsed struct {
+		Candidates []struct {
+			Content geminiContent `json:"content"`
+		} `json:"candidates"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ChatMessage{}, err
+	}
+
+	msg := ChatMessage{Role: "assistant"}
+	if len(parsed.Candidates) == 0 {
+		return msg, nil
+	}
+	for i, part := range parsed.Candidates[0].Content.Parts {
+		if part.Text != "" {
+			msg.Content += part.Text
+		}
+		if part.FunctionCall != nil {
+			args, _ := json.Marshal(part.FunctionCall.Args)
+			tc := ToolCall{ID: "call_" + strconv.Itoa(i), Type: "function"}
+			tc.Function.Name = part.FunctionCall.Name
+			tc.Function.Arguments = string(args)
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+	}
+	return msg, nil
+}
+
+// StreamResponse parses the `alt=sse` streamGenerateContent format: each
+// `data: {...}` line is a full incremental GenerateContentResponse (Gemini
+// does not sub-delta function-call arguments the way OpenAI/Anthropic do,
+// so each functionCall part arrives complete and is emitted as an
+// immediate ToolCallStart/ToolCallEnd pair).
+func (GeminiProvider) StreamResponse(body io.Reader, ch chan<- Chunk) {
+	type streamResponse struct {
+		Candidates []struct {
+			Content geminiContent `json:"content"`
+		} `json:"candidates"`
+	}
+
+	index := 0
+	scanner := bufio.NewScanner(bufio.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		var parsed streamResponse
+		if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+			continue
+		}
+		if len(parsed.Candidates) == 0 {
+			continue
+		}
+
+		for _, part := range parsed.Candidates[0].Content.Parts {
+			if part.Text != "" {
+				ch <- Chunk{Type: TextDelta, Text: part.Text}
+			}
+			if part.FunctionCall != nil {
+				args, _ := json.Marshal(part.FunctionCall.Args)
+				tc := ToolCall{ID: "call_" + strconv.Itoa(index), Type: "function"}
+				tc.Function.Name = part.FunctionCall.Name
+				tc.Function.Arguments = string(args)
+				ch <- Chunk{Type: ToolCallStart, Index: index, ToolCall: tc}
+				ch <- Chunk{Type: ToolCallEnd, Index: index, ToolCall: tc}
+				index++
+			}
+		}
+	}
+}
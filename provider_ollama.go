@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// OllamaProvider talks to a local Ollama server's /api/chat endpoint.
+// Ollama's wire format is OpenAI-flavored but not byte-compatible: it
+// streams newline-delimited JSON objects (no "data:" / "[DONE]" SSE
+// framing) and, unlike OpenAI or Anthropic, emits each tool call whole
+// rather than as incremental argument deltas.
+type OllamaProvider struct{}
+
+func (OllamaProvider) Name() string            { return "ollama" }
+func (OllamaProvider) DefaultEndpoint() string { return "http://localhost:11434/api/chat" }
+func (OllamaProvider) DefaultModel() string    { return "qwen2.5-coder" }
+
+// AuthHeaders is empty: a local Ollama server has no API key to send.
+func (OllamaProvider) AuthHeaders(apiKey string) map[string]string {
+	return map[string]string{}
+}
+
+func (OllamaProvider) RequestURL(p *APIProvider, req InferenceRequest) string {
+	return p.BaseURL
+}
+
+type ollamaToolDef struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+// ollamaToolCall mirrors Ollama's tool_calls wire shape, where, unlike
+// OpenAI, function.arguments is a JSON object rather than a stringified
+// JSON blob. Converted to/from the shared string-typed ToolCall at the
+// wire boundary, the same way GeminiProvider handles its object-shaped
+// function call args.
+type ollamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+func toOllamaToolCall(tc ToolCall) ollamaToolCall {
+	var otc ollamaToolCall
+	otc.Function.Name = tc.Function.Name
+	if tc.Function.Arguments != "" {
+		json.Unmarshal([]byte(tc.Function.Arguments), &otc.Function.Arguments)
+	}
+	return otc
+}
+
+func fromOllamaToolCall(otc ollamaToolCall) ToolCall {
+	var tc ToolCall
+	tc.Type = "function"
+	tc.Function.Name = otc.Function.Name
+	args, _ := json.Marshal(otc.Function.Arguments)
+	tc.Function.Arguments = string(args)
+	return tc
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaToolDef `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+}
+
+func (OllamaProvider) BuildRequest(req InferenceRequest) ([]byte, error) {
+	messages := []ollamaMessage{}
+	if req.SystemPrompt != "" {
+		messages = append(messages, ollamaMessage{Role: "system", Content: req.SystemPrompt})
+	}
+	for _, msg := range req.Messages {
+		var toolCalls []ollamaToolCall
+		for _, tc := range msg.ToolCalls {
+			toolCalls = append(toolCalls, toOllamaToolCall(tc))
+		}
+		messages = append(messages, ollamaMessage{Role: msg.Role, Content: msg.Content, ToolCalls: toolCalls})
+	}
+
+	tools := []ollamaToolDef{}
+	for _, tool := range req.Tools {
+		td := ollamaToolDef{Type: "function"}
+		td.Function.Name = tool.Name
+		td.Function.Description = tool.Description
+		td.Function.Parameters = toolParametersFor(tool)
+		tools = append(tools, td)
+	}
+
+	return json.Marshal(ollamaRequest{
+		Model:    req.Model,
+		Messages: messages,
+		Tools:    tools,
+		Stream:   req.Stream,
+	})
+}
+
+func (OllamaProvider) ParseResponse(body []byte) (ChatMessage, error) {
+	var parsed struct {
+		Message ollamaMessage `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ChatMessage{}, err
+	}
+	var toolCalls []ToolCall
+	for _, otc := range parsed.Message.ToolCalls {
+		toolCalls = append(toolCalls, fromOllamaToolCall(otc))
+	}
+	return ChatMessage{Role: "assistant", Content: parsed.Message.Content, ToolCalls: toolCalls}, nil
+}
+
+// StreamResponse reads Ollama's newline-delimited JSON stream. Each line is
+// a full message fragment; tool calls arrive whole (no per-argument
+// deltas), so each is emitted as an immediate ToolCallStart/ToolCallEnd
+// pair.
+func (OllamaProvider) StreamResponse(body io.Reader, ch chan<- Chunk) {
+	type streamLine struct {
+		Message ollamaMessage `json:"message"`
+		Done    bool          `json:"done"`
+	}
+
+	index := 0
+	scanner := bufio.NewScanner(bufio.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var parsed streamLine
+		if err := json.Unmarshal(line, &parsed); err != nil {
+			continue
+		}
+
+		if parsed.Message.Content != "" {
+			ch <- Chunk{Type: TextDelta, Text: parsed.Message.Content}
+		}
+		for _, otc := range parsed.Message.ToolCalls {
+			tc := fromOllamaToolCall(otc)
+			ch <- Chunk{Type: ToolCallStart, Index: index, ToolCall: tc}
+			ch <- Chunk{Type: ToolCallEnd, Index: index, ToolCall: tc}
+			index++
+		}
+
+		if parsed.Done {
+			return
+		}
+	}
+}
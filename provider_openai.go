@@ -0,0 +1,32 @@
+package main
+
+import "io"
+
+// OpenAIProvider talks to OpenAI's native /v1/chat/completions endpoint,
+// which is the same shape OpenRouter cloned, so it reuses the shared
+// OpenAI-compatible request/response/SSE helpers.
+type OpenAIProvider struct{}
+
+func (OpenAIProvider) Name() string            { return "openai" }
+func (OpenAIProvider) DefaultEndpoint() string { return "https://api.openai.com/v1/chat/completions" }
+func (OpenAIProvider) DefaultModel() string    { return "gpt-4o" }
+
+func (OpenAIProvider) AuthHeaders(apiKey string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + apiKey}
+}
+
+func (OpenAIProvider) RequestURL(p *APIProvider, req InferenceRequest) string {
+	return p.BaseURL
+}
+
+func (OpenAIProvider) BuildRequest(req InferenceRequest) ([]byte, error) {
+	return buildOpenAICompatRequest(req)
+}
+
+func (OpenAIProvider) ParseResponse(body []byte) (ChatMessage, error) {
+	return parseOpenAICompatResponse(body)
+}
+
+func (OpenAIProvider) StreamResponse(body io.Reader, ch chan<- Chunk) {
+	streamOpenAICompatSSE(body, ch)
+}
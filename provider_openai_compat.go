@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// The OpenAI chat-completions wire format (request, response, and SSE
+// framing) is shared verbatim by OpenRouter, OpenAI itself, and Ollama's
+// OpenAI-compatible surface, so it lives here once instead of being
+// duplicated across provider_openrouter.go / provider_openai.go.
+
+type openAICompatToolDef struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		Parameters  map[string]interface{} `json:"parameters"`
+	} `json:"function"`
+}
+
+type openAICompatRequest struct {
+	Model     string                `json:"model"`
+	Messages  []ChatMessage         `json:"messages"`
+	Tools     []openAICompatToolDef `json:"tools,omitempty"`
+	MaxTokens int                   `json:"max_tokens,omitempty"`
+	Stream    bool                  `json:"stream,omitempty"`
+}
+
+type openAICompatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   string     `json:"content"`
+			ToolCalls []ToolCall `json:"tool_calls"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// buildOpenAICompatRequest marshals an InferenceRequest into the shared
+// OpenAI chat-completions shape, prepending the system prompt as a
+// `system`-role message the way this API expects it.
+func buildOpenAICompatRequest(req InferenceRequest) ([]byte, error) {
+	tools := []openAICompatToolDef{}
+	for _, tool := range req.Tools {
+		td := openAICompatToolDef{Type: "function"}
+		td.Function.Name = tool.Name
+		td.Function.Description = tool.Description
+		td.Function.Parameters = toolParametersFor(tool)
+		tools = append(tools, td)
+	}
+
+	messages := req.Messages
+	if req.SystemPrompt != "" {
+		messages = append([]ChatMessage{{Role: "system", Content: req.SystemPrompt}}, req.Messages...)
+	}
+
+	return json.Marshal(openAICompatRequest{
+		Model:     req.Model,
+		Messages:  messages,
+		Tools:     tools,
+		MaxTokens: req.MaxTokens,
+		Stream:    req.Stream,
+	})
+}
+
+func parseOpenAICompatResponse(body []byte) (ChatMessage, error) {
+	var parsed openAICompatResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return ChatMessage{}, err
+	}
+	if len(parsed.Choices) == 0 {
+		return ChatMessage{Role: "assistant"}, nil
+	}
+	msg := parsed.Choices[0].Message
+	return ChatMessage{Role: "assistant", Content: msg.Content, ToolCalls: msg.ToolCalls}, nil
+}
+
+// streamOpenAICompatSSE parses OpenAI-style SSE: each `data: {...}` line is
+// a chunk with `choices[0].delta.content` / `.tool_calls[].function.arguments`
+// fragments, terminated by a `data: [DONE]` line.
+func streamOpenAICompatSSE(body io.Reader, ch chan<- Chunk) {
+	type deltaToolCall struct {
+		Index    int    `json:"index"`
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	}
+
+	type streamChunk struct {
+		Choices []struct {
+			Delta struct {
+				Content   string          `json:"content"`
+				ToolCalls []deltaToolCall `json:"tool_calls"`
+			} `json:"delta"`
+		} `json:"choices"`
+	}
+
+	seen := map[int]bool{}
+
+	scanner := bufio.NewScanner(bufio.NewReader(body))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" || data == "" {
+			continue
+		}
+
+		var parsed streamChunk
+		if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+			continue
+		}
+		if len(parsed.Choices) == 0 {
+			continue
+		}
+
+		delta := parsed.Choices[0].Delta
+		if delta.Content != "" {
+			ch <- Chunk{Type: TextDelta, Text: delta.Content}
+		}
+
+		for _, tc := range delta.ToolCalls {
+			if !seen[tc.Index] {
+				seen[tc.Index] = true
+				ch <- Chunk{
+					Type:  ToolCallStart,
+					Index: tc.Index,
+					ToolCall: ToolCall{
+						ID:   tc.ID,
+						Type: "function",
+						Function: struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						}{Name: tc.Function.Name},
+					},
+				}
+			}
+			if tc.Function.Arguments != "" {
+				ch <- Chunk{Type: ToolCallArgDelta, Index: tc.Index, ArgsDelta: tc.Function.Arguments}
+			}
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import "io"
+
+// OpenRouterProvider talks to OpenRouter's OpenAI-compatible
+// /api/v1/chat/completions endpoint.
+type OpenRouterProvider struct{}
+
+func (OpenRouterProvider) Name() string { return "openrouter" }
+func (OpenRouterProvider) DefaultEndpoint() string {
+	return "https://openrouter.ai/api/v1/chat/completions"
+}
+func (OpenRouterProvider) DefaultModel() string { return "anthropic/claude-sonnet-4.5" }
+
+func (OpenRouterProvider) AuthHeaders(apiKey string) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + apiKey,
+		"HTTP-Referer":  "https://praktor.ai",
+		"X-Title":       "Praktor",
+	}
+}
+
+func (OpenRouterProvider) RequestURL(p *APIProvider, req InferenceRequest) string {
+	return p.BaseURL
+}
+
+func (OpenRouterProvider) BuildRequest(req InferenceRequest) ([]byte, error) {
+	return buildOpenAICompatRequest(req)
+}
+
+func (OpenRouterProvider) ParseResponse(body []byte) (ChatMessage, error) {
+	return parseOpenAICompatResponse(body)
+}
+
+func (OpenRouterProvider) StreamResponse(body io.Reader, ch chan<- Chunk) {
+	streamOpenAICompatSSE(body, ch)
+}
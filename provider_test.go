@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func drainStreamResponse(provider Provider, body string) []Chunk {
+	ch := make(chan Chunk, 64)
+	provider.StreamResponse(strings.NewReader(body), ch)
+	close(ch)
+	var chunks []Chunk
+	for c := range ch {
+		chunks = append(chunks, c)
+	}
+	return chunks
+}
+
+func TestOpenAICompatSSEEmitsTextAndToolCallDeltas(t *testing.T) {
+	body := `data: {"choices":[{"delta":{"content":"hel"}}]}` + "\n\n" +
+		`data: {"choices":[{"delta":{"content":"lo"}}]}` + "\n\n" +
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"read_file","arguments":""}}]}}]}` + "\n\n" +
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":\"a.go\"}"}}]}}]}` + "\n\n" +
+		"data: [DONE]\n\n"
+
+	chunks := drainStreamResponse(OpenAIProvider{}, body)
+
+	var text strings.Builder
+	var sawStart, sawDelta bool
+	for _, c := range chunks {
+		switch c.Type {
+		case TextDelta:
+			text.WriteString(c.Text)
+		case ToolCallStart:
+			sawStart = true
+			if c.ToolCall.ID != "call_1" {
+				t.Errorf("ToolCallStart.ID = %q, want call_1", c.ToolCall.ID)
+			}
+		case ToolCallArgDelta:
+			sawDelta = true
+			if c.ArgsDelta != `{"path":"a.go"}` {
+				t.Errorf("ArgsDelta = %q, want %q", c.ArgsDelta, `{"path":"a.go"}`)
+			}
+		}
+	}
+	if text.String() != "hello" {
+		t.Errorf("text = %q, want %q", text.String(), "hello")
+	}
+	if !sawStart || !sawDelta {
+		t.Errorf("missing ToolCallStart/ToolCallArgDelta, got %+v", chunks)
+	}
+}
+
+func TestAnthropicSSEBuffersArgsUntilBlockStop(t *testing.T) {
+	body := "event: content_block_start\n" +
+		`data: {"index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"read_file"}}` + "\n\n" +
+		"event: content_block_delta\n" +
+		`data: {"index":0,"delta":{"type":"input_json_delta","partial_json":"{\"path\":"}}` + "\n\n" +
+		"event: content_block_delta\n" +
+		`data: {"index":0,"delta":{"type":"input_json_delta","partial_json":"\"a.go\"}"}}` + "\n\n" +
+		"event: content_block_stop\n" +
+		`data: {"index":0}` + "\n\n"
+
+	chunks := drainStreamResponse(AnthropicProvider{}, body)
+
+	var argDeltas strings.Builder
+	sawStart := false
+	for _, c := range chunks {
+		switch c.Type {
+		case ToolCallStart:
+			sawStart = true
+			if c.ToolCall.ID != "toolu_1" || c.ToolCall.Function.Name != "read_file" {
+				t.Errorf("ToolCallStart = %+v, want id toolu_1 / name read_file", c.ToolCall)
+			}
+		case ToolCallArgDelta:
+			argDeltas.WriteString(c.ArgsDelta)
+		}
+	}
+	if !sawStart {
+		t.Fatalf("missing ToolCallStart, got %+v", chunks)
+	}
+	if argDeltas.String() != `{"path":"a.go"}` {
+		t.Errorf("concatenated arg deltas = %q, want %q", argDeltas.String(), `{"path":"a.go"}`)
+	}
+}
+
+func TestOllamaNDJSONEmitsWholeToolCallsAndStopsAtDone(t *testing.T) {
+	// Ollama's real wire format sends function.arguments as a JSON object,
+	// not a stringified blob like OpenAI.
+	body := `{"message":{"role":"assistant","content":"thinking..."},"done":false}` + "\n" +
+		`{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"list_files","arguments":{"path":"."}}}]},"done":false}` + "\n" +
+		`{"message":{"role":"assistant","content":""},"done":true}` + "\n" +
+		`{"message":{"role":"assistant","content":"should not be read"},"done":false}` + "\n"
+
+	chunks := drainStreamResponse(OllamaProvider{}, body)
+
+	var text strings.Builder
+	var starts, ends int
+	for _, c := range chunks {
+		switch c.Type {
+		case TextDelta:
+			text.WriteString(c.Text)
+		case ToolCallStart:
+			starts++
+		case ToolCallEnd:
+			ends++
+			if c.ToolCall.Function.Name != "list_files" {
+				t.Errorf("ToolCallEnd.Function.Name = %q, want list_files", c.ToolCall.Function.Name)
+			}
+			if c.ToolCall.Function.Arguments != `{"path":"."}` {
+				t.Errorf("ToolCallEnd.Function.Arguments = %q, want %q", c.ToolCall.Function.Arguments, `{"path":"."}`)
+			}
+		}
+	}
+	if text.String() != "thinking..." {
+		t.Errorf("text = %q, want %q", text.String(), "thinking...")
+	}
+	if starts != 1 || ends != 1 {
+		t.Errorf("got %d starts / %d ends, want 1/1 (whole tool call, no deltas)", starts, ends)
+	}
+}
+
+func TestGeminiSSEEmitsTextAndCompleteFunctionCalls(t *testing.T) {
+	body := `data: {"candidates":[{"content":{"parts":[{"text":"hel"}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"text":"lo"}]}}]}` + "\n\n" +
+		`data: {"candidates":[{"content":{"parts":[{"functionCall":{"name":"read_file","args":{"path":"a.go"}}}]}}]}` + "\n\n"
+
+	chunks := drainStreamResponse(GeminiProvider{}, body)
+
+	var text strings.Builder
+	var starts, ends int
+	for _, c := range chunks {
+		switch c.Type {
+		case TextDelta:
+			text.WriteString(c.Text)
+		case ToolCallStart:
+			starts++
+		case ToolCallEnd:
+			ends++
+			if c.ToolCall.Function.Name != "read_file" {
+				t.Errorf("ToolCallEnd.Function.Name = %q, want read_file", c.ToolCall.Function.Name)
+			}
+		}
+	}
+	if text.String() != "hello" {
+		t.Errorf("text = %q, want %q", text.String(), "hello")
+	}
+	if starts != 1 || ends != 1 {
+		t.Errorf("got %d starts / %d ends, want 1/1 (Gemini emits function calls whole)", starts, ends)
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS conversations (
+	id TEXT PRIMARY KEY,
+	title TEXT NOT NULL,
+	head_message_id TEXT,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+	id TEXT PRIMARY KEY,
+	conversation_id TEXT NOT NULL REFERENCES conversations(id),
+	parent_id TEXT,
+	role TEXT NOT NULL,
+	content TEXT NOT NULL,
+	tool_call_id TEXT,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tool_calls (
+	id TEXT PRIMARY KEY,
+	message_id TEXT NOT NULL REFERENCES messages(id),
+	name TEXT NOT NULL,
+	arguments TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_messages_parent ON messages(parent_id);
+`
+
+// SQLiteStore is the ConversationStore backing `praktor`'s conversation
+// subcommands (new/reply/view/ls/rm/branch).
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// defaultStorePath returns $XDG_DATA_HOME/praktor/conversations.db,
+// falling back to the OS default user config dir when XDG_DATA_HOME is
+// unset, and creates the containing directory.
+func defaultStorePath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = dir
+	}
+
+	dbDir := filepath.Join(dataHome, "praktor")
+	if err := os.MkdirAll(dbDir, 0755); err != nil {
+		return "", fmt.Errorf("creating store directory: %w", err)
+	}
+	return filepath.Join(dbDir, "conversations.db"), nil
+}
+
+// OpenSQLiteStore opens (creating if necessary) the SQLite database at
+// path and ensures its schema is up to date.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating store schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) CreateConversation(title string) (Conversation, error) {
+	conv := Conversation{
+		ID:        newID(),
+		Title:     title,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := s.db.Exec(
+		`INSERT INTO conversations (id, title, head_message_id, created_at) VALUES (?, ?, NULL, ?)`,
+		conv.ID, conv.Title, conv.CreatedAt,
+	)
+	if err != nil {
+		return Conversation{}, fmt.Errorf("creating conversation: %w", err)
+	}
+	return conv, nil
+}
+
+func (s *SQLiteStore) GetConversation(id string) (Conversation, error) {
+	var conv Conversation
+	var headID sql.NullString
+	row := s.db.QueryRow(`SELECT id, title, head_message_id, created_at FROM conversations WHERE id = ?`, id)
+	if err := row.Scan(&conv.ID, &conv.Title, &headID, &conv.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return Conversation{}, fmt.Errorf("conversation %q not found", id)
+		}
+		return Conversation{}, err
+	}
+	conv.HeadID = headID.String
+	return conv, nil
+}
+
+func (s *SQLiteStore) ListConversations() ([]Conversation, error) {
+	rows, err := s.db.Query(`SELECT id, title, head_message_id, created_at FROM conversations ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []Conversation
+	for rows.Next() {
+		var conv Conversation
+		var headID sql.NullString
+		if err := rows.Scan(&conv.ID, &conv.Title, &headID, &conv.CreatedAt); err != nil {
+			return nil, err
+		}
+		conv.HeadID = headID.String
+		conversations = append(conversations, conv)
+	}
+	return conversations, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteConversation(id string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM tool_calls WHERE message_id IN (SELECT id FROM messages WHERE conversation_id = ?)`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM messages WHERE conversation_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM conversations WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) SetHead(conversationID, messageID string) error {
+	_, err := s.db.Exec(`UPDATE conversations SET head_message_id = ? WHERE id = ?`, messageID, conversationID)
+	return err
+}
+
+func (s *SQLiteStore) AppendMessage(msg StoredMessage) (StoredMessage, error) {
+	if msg.ID == "" {
+		msg.ID = newID()
+	}
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+
+	var parentID, toolCallID sql.NullString
+	if msg.ParentID != "" {
+		parentID = sql.NullString{String: msg.ParentID, Valid: true}
+	}
+	if msg.ToolCallID != "" {
+		toolCallID = sql.NullString{String: msg.ToolCallID, Valid: true}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return StoredMessage{}, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`INSERT INTO messages (id, conversation_id, parent_id, role, content, tool_call_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		msg.ID, msg.ConversationID, parentID, msg.Role, msg.Content, toolCallID, msg.CreatedAt,
+	)
+	if err != nil {
+		return StoredMessage{}, fmt.Errorf("inserting message: %w", err)
+	}
+
+	for _, tc := range msg.ToolCalls {
+		args, err := json.Marshal(tc.Function.Arguments)
+		if err != nil {
+			return StoredMessage{}, err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO tool_calls (id, message_id, name, arguments) VALUES (?, ?, ?, ?)`,
+			tc.ID, msg.ID, tc.Function.Name, string(args),
+		); err != nil {
+			return StoredMessage{}, fmt.Errorf("inserting tool call: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE conversations SET head_message_id = ? WHERE id = ?`, msg.ID, msg.ConversationID); err != nil {
+		return StoredMessage{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return StoredMessage{}, err
+	}
+
+	return msg, nil
+}
+
+func (s *SQLiteStore) History(leafID string) ([]StoredMessage, error) {
+	if leafID == "" {
+		return nil, nil
+	}
+
+	var chain []StoredMessage
+	currentID := leafID
+	for currentID != "" {
+		msg, err := s.getMessage(currentID)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, msg)
+		currentID = msg.ParentID
+	}
+
+	// chain is leaf-first; reverse to root-first.
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+func (s *SQLiteStore) getMessage(id string) (StoredMessage, error) {
+	var msg StoredMessage
+	var parentID, toolCallID sql.NullString
+	row := s.db.QueryRow(
+		`SELECT id, conversation_id, parent_id, role, content, tool_call_id, created_at FROM messages WHERE id = ?`,
+		id,
+	)
+	if err := row.Scan(&msg.ID, &msg.ConversationID, &parentID, &msg.Role, &msg.Content, &toolCallID, &msg.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return StoredMessage{}, errMessageNotFound(id)
+		}
+		return StoredMessage{}, err
+	}
+	msg.ParentID = parentID.String
+	msg.ToolCallID = toolCallID.String
+
+	rows, err := s.db.Query(`SELECT id, name, arguments FROM tool_calls WHERE message_id = ?`, id)
+	if err != nil {
+		return StoredMessage{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tc ToolCall
+		var args string
+		if err := rows.Scan(&tc.ID, &tc.Function.Name, &args); err != nil {
+			return StoredMessage{}, err
+		}
+		tc.Type = "function"
+		_ = json.Unmarshal([]byte(args), &tc.Function.Arguments)
+		msg.ToolCalls = append(msg.ToolCalls, tc)
+	}
+
+	return msg, rows.Err()
+}
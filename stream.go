@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ChunkType identifies the kind of incremental event emitted while
+// streaming an inference response.
+type ChunkType string
+
+const (
+	TextDelta        ChunkType = "text_delta"
+	ToolCallStart    ChunkType = "tool_call_start"
+	ToolCallArgDelta ChunkType = "tool_call_arg_delta"
+	ToolCallEnd      ChunkType = "tool_call_end"
+	Done             ChunkType = "done"
+)
+
+// Chunk is one incremental event from a streamed response. Text and
+// tool-call argument fragments must be concatenated by the receiver;
+// ToolCallEnd carries the fully assembled ToolCall.
+type Chunk struct {
+	Type      ChunkType
+	Text      string
+	Index     int
+	ToolCall  ToolCall
+	ArgsDelta string
+}
+
+// streamInference issues a streaming (`stream: true`) request through the
+// Agent's selected Provider and returns a channel of incremental Chunks.
+// The channel is closed after a Done chunk is sent (or immediately on a
+// request-level error delivered via err).
+func (a *Agent) streamInference(ctx context.Context, conversation []ChatMessage) (<-chan Chunk, error) {
+	provider, err := lookupProvider(a.provider.ProviderType)
+	if err != nil {
+		return nil, err
+	}
+
+	inferReq := InferenceRequest{
+		Model:        a.provider.Model,
+		SystemPrompt: a.systemPrompt,
+		Messages:     conversation,
+		Tools:        a.tools,
+		MaxTokens:    defaultMaxTokens,
+		Stream:       true,
+	}
+
+	reqBody, err := provider.BuildRequest(inferReq)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", provider.RequestURL(a.provider, inferReq), bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	for k, v := range provider.AuthHeaders(a.provider.APIKey) {
+		httpReq.Header.Set(k, v)
+	}
+	for k, v := range a.provider.Headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s", string(body))
+	}
+
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		provider.StreamResponse(resp.Body, ch)
+		ch <- Chunk{Type: Done}
+	}()
+
+	return ch, nil
+}
+
+// assembleToolCalls reconstructs complete ToolCalls from a stream of
+// ToolCallStart/ToolCallArgDelta/ToolCallEnd chunks, in first-seen index
+// order. For providers that emit ToolCallEnd with fully-formed arguments
+// (Anthropic) those take precedence; otherwise arguments are the
+// concatenation of every ArgsDelta seen for that index (OpenAI-style).
+type toolCallAssembler struct {
+	order []int
+	calls map[int]*ToolCall
+	args  map[int]*strings.Builder
+	ended map[int]bool
+}
+
+func newToolCallAssembler() *toolCallAssembler {
+	return &toolCallAssembler{
+		calls: map[int]*ToolCall{},
+		args:  map[int]*strings.Builder{},
+		ended: map[int]bool{},
+	}
+}
+
+func (t *toolCallAssembler) handle(chunk Chunk) {
+	switch chunk.Type {
+	case ToolCallStart:
+		if _, ok := t.calls[chunk.Index]; !ok {
+			tc := chunk.ToolCall
+			t.calls[chunk.Index] = &tc
+			t.args[chunk.Index] = &strings.Builder{}
+			t.order = append(t.order, chunk.Index)
+		}
+	case ToolCallArgDelta:
+		if b, ok := t.args[chunk.Index]; ok {
+			b.WriteString(chunk.ArgsDelta)
+		}
+	case ToolCallEnd:
+		if tc, ok := t.calls[chunk.Index]; ok {
+			tc.Function.Arguments = chunk.ToolCall.Function.Arguments
+			t.ended[chunk.Index] = true
+		}
+	}
+}
+
+func (t *toolCallAssembler) finish() []ToolCall {
+	calls := make([]ToolCall, 0, len(t.order))
+	for _, idx := range t.order {
+		tc := *t.calls[idx]
+		if !t.ended[idx] {
+			tc.Function.Arguments = t.args[idx].String()
+		}
+		if tc.ID == "" {
+			tc.ID = "call_" + strconv.Itoa(idx)
+		}
+		calls = append(calls, tc)
+	}
+	return calls
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToolCallAssemblerConcatenatesArgDeltas(t *testing.T) {
+	asm := newToolCallAssembler()
+	asm.handle(Chunk{Type: ToolCallStart, Index: 0, ToolCall: ToolCall{ID: "call_1", Function: struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	}{Name: "read_file"}}})
+	asm.handle(Chunk{Type: ToolCallArgDelta, Index: 0, ArgsDelta: `{"path":`})
+	asm.handle(Chunk{Type: ToolCallArgDelta, Index: 0, ArgsDelta: `"a.go"}`})
+
+	got := asm.finish()
+	want := []ToolCall{{ID: "call_1", Function: struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	}{Name: "read_file", Arguments: `{"path":"a.go"}`}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestToolCallAssemblerEndOverridesArgDeltas(t *testing.T) {
+	// Anthropic emits ToolCallEnd with the fully-formed arguments; any
+	// ArgsDelta fragments seen before it must be ignored in favor of it.
+	asm := newToolCallAssembler()
+	asm.handle(Chunk{Type: ToolCallStart, Index: 0, ToolCall: ToolCall{ID: "call_1"}})
+	asm.handle(Chunk{Type: ToolCallArgDelta, Index: 0, ArgsDelta: `{"partial`})
+	asm.handle(Chunk{Type: ToolCallEnd, Index: 0, ToolCall: ToolCall{Function: struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	}{Arguments: `{"complete":true}`}}})
+
+	got := asm.finish()
+	if len(got) != 1 || got[0].Function.Arguments != `{"complete":true}` {
+		t.Errorf("got %+v, want arguments %q", got, `{"complete":true}`)
+	}
+}
+
+func TestToolCallAssemblerPreservesFirstSeenOrder(t *testing.T) {
+	asm := newToolCallAssembler()
+	asm.handle(Chunk{Type: ToolCallStart, Index: 2, ToolCall: ToolCall{ID: "call_c"}})
+	asm.handle(Chunk{Type: ToolCallStart, Index: 0, ToolCall: ToolCall{ID: "call_a"}})
+	asm.handle(Chunk{Type: ToolCallEnd, Index: 2, ToolCall: ToolCall{}})
+	asm.handle(Chunk{Type: ToolCallEnd, Index: 0, ToolCall: ToolCall{}})
+
+	got := asm.finish()
+	if len(got) != 2 || got[0].ID != "call_c" || got[1].ID != "call_a" {
+		t.Errorf("got %+v, want call_c then call_a (first-seen order, not index order)", got)
+	}
+}
+
+func TestToolCallAssemblerAssignsFallbackIDFromIndex(t *testing.T) {
+	// Gemini/Ollama never set ToolCall.ID; finish() must synthesize one
+	// from the index so downstream tool_call_id pairing still works.
+	asm := newToolCallAssembler()
+	asm.handle(Chunk{Type: ToolCallStart, Index: 3, ToolCall: ToolCall{}})
+	asm.handle(Chunk{Type: ToolCallEnd, Index: 3, ToolCall: ToolCall{}})
+
+	got := asm.finish()
+	if len(got) != 1 || got[0].ID != "call_3" {
+		t.Errorf("got %+v, want a synthesized ID of call_3", got)
+	}
+}
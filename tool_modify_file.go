@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ModifyFileDefinition is the multi-edit successor to edit_file: it applies
+// a batch of edits to a single in-memory snapshot of the file, all or
+// nothing, and returns a unified diff of what changed instead of "OK".
+var ModifyFileDefinition = ToolDefinition{
+	Name: "modify_file",
+	Description: `Apply a batch of edits to a text file and return a unified diff of the result.
+
+Each edit is one of:
+  {"type": "replace", "old_str": "...", "new_str": "..."} - old_str must match exactly once in the file at the point this edit is applied.
+  {"type": "insert_after_line", "line": N, "content": "..."} - inserts content as a new line after line N (0 inserts at the top of the file).
+  {"type": "delete_range", "start_line": N, "end_line": M} - deletes lines N through M inclusive.
+
+Edits are applied in order against a single snapshot read at the start of the call: line numbers for
+insert_after_line/delete_range refer to the file as it stands after every preceding edit in this same call.
+If any edit fails to apply (a replace with zero or multiple matches, or an out-of-range line), no edit in
+the batch is written to disk and the error lists candidate line numbers where relevant.`,
+	InputSchema: ModifyFileInputSchema,
+	Function:    ModifyFile,
+}
+
+type ModifyEdit struct {
+	Type      string `json:"type" jsonschema_description:"One of: replace, insert_after_line, delete_range"`
+	OldStr    string `json:"old_str,omitempty" jsonschema_description:"For replace: text to search for, must match exactly once"`
+	NewStr    string `json:"new_str,omitempty" jsonschema_description:"For replace: text to replace old_str with"`
+	Line      int    `json:"line,omitempty" jsonschema_description:"For insert_after_line: the line to insert after (0 inserts at the top)"`
+	Content   string `json:"content,omitempty" jsonschema_description:"For insert_after_line: the line content to insert"`
+	StartLine int    `json:"start_line,omitempty" jsonschema_description:"For delete_range: first line to delete (1-indexed)"`
+	EndLine   int    `json:"end_line,omitempty" jsonschema_description:"For delete_range: last line to delete (1-indexed, inclusive)"`
+}
+
+type ModifyFileInput struct {
+	Path  string       `json:"path" jsonschema_description:"The path to the file"`
+	Edits []ModifyEdit `json:"edits" jsonschema_description:"The edits to apply, in order"`
+}
+
+var ModifyFileInputSchema = GenerateSchema[ModifyFileInput]()
+
+func ModifyFile(input []byte) (string, error) {
+	modifyInput := ModifyFileInput{}
+	if err := json.Unmarshal(input, &modifyInput); err != nil {
+		return "", err
+	}
+
+	if modifyInput.Path == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if len(modifyInput.Edits) == 0 {
+		return "", fmt.Errorf("at least one edit is required")
+	}
+
+	resolved, err := resolveWorkspacePath(modifyInput.Path)
+	if err != nil {
+		return "", err
+	}
+
+	original, err := os.ReadFile(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	trailingNewline := len(original) > 0 && original[len(original)-1] == '\n'
+	beforeLines := splitLines(string(original))
+	working := beforeLines
+
+	for i, edit := range modifyInput.Edits {
+		var err error
+		working, err = applyModifyEdit(working, edit)
+		if err != nil {
+			return "", fmt.Errorf("edit %d (%s): %w", i, edit.Type, err)
+		}
+	}
+
+	newContent := strings.Join(working, "\n")
+	if trailingNewline {
+		newContent += "\n"
+	}
+	if err := os.WriteFile(resolved, []byte(newContent), 0644); err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(modifyInput.Path, beforeLines, working), nil
+}
+
+func applyModifyEdit(lines []string, edit ModifyEdit) ([]string, error) {
+	switch edit.Type {
+	case "replace":
+		return applyReplace(lines, edit.OldStr, edit.NewStr)
+	case "insert_after_line":
+		return applyInsertAfterLine(lines, edit.Line, edit.Content)
+	case "delete_range":
+		return applyDeleteRange(lines, edit.StartLine, edit.EndLine)
+	default:
+		return nil, fmt.Errorf("unknown edit type %q", edit.Type)
+	}
+}
+
+func applyReplace(lines []string, oldStr, newStr string) ([]string, error) {
+	if oldStr == newStr {
+		return nil, fmt.Errorf("old_str and new_str must be different")
+	}
+
+	content := strings.Join(lines, "\n")
+	count := strings.Count(content, oldStr)
+
+	if count == 0 {
+		return nil, fmt.Errorf("old_str not found")
+	}
+	if count > 1 {
+		var candidates []int
+		for i, line := range lines {
+			if strings.Contains(line, strings.SplitN(oldStr, "\n", 2)[0]) {
+				candidates = append(candidates, i+1)
+			}
+		}
+		return nil, fmt.Errorf("old_str matches %d times, expected exactly 1 (candidate lines: %v)", count, candidates)
+	}
+
+	return splitLines(strings.Replace(content, oldStr, newStr, 1)), nil
+}
+
+func applyInsertAfterLine(lines []string, line int, content string) ([]string, error) {
+	if line < 0 || line > len(lines) {
+		return nil, fmt.Errorf("line %d is out of range (file has %d lines)", line, len(lines))
+	}
+
+	result := make([]string, 0, len(lines)+1)
+	result = append(result, lines[:line]...)
+	result = append(result, content)
+	result = append(result, lines[line:]...)
+	return result, nil
+}
+
+func applyDeleteRange(lines []string, start, end int) ([]string, error) {
+	if start < 1 || end < start || end > len(lines) {
+		return nil, fmt.Errorf("range %d-%d is out of range (file has %d lines)", start, end, len(lines))
+	}
+
+	result := make([]string, 0, len(lines)-(end-start+1))
+	result = append(result, lines[:start-1]...)
+	result = append(result, lines[end:]...)
+	return result, nil
+}
+
+// splitLines splits content into lines without keeping trailing empty
+// strings caused by a final newline, so line counts match what an editor
+// would show.
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
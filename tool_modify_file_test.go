@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApplyModifyEditReplace(t *testing.T) {
+	lines := []string{"one", "two", "three"}
+	got, err := applyModifyEdit(lines, ModifyEdit{Type: "replace", OldStr: "two", NewStr: "TWO"})
+	if err != nil {
+		t.Fatalf("applyModifyEdit: %v", err)
+	}
+	want := []string{"one", "TWO", "three"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApplyModifyEditReplaceRejectsAmbiguousMatch(t *testing.T) {
+	lines := []string{"dup", "dup", "three"}
+	if _, err := applyModifyEdit(lines, ModifyEdit{Type: "replace", OldStr: "dup", NewStr: "x"}); err == nil {
+		t.Fatal("expected error for old_str matching more than once, got nil")
+	}
+}
+
+func TestApplyModifyEditReplaceRejectsNoMatch(t *testing.T) {
+	lines := []string{"one", "two", "three"}
+	if _, err := applyModifyEdit(lines, ModifyEdit{Type: "replace", OldStr: "missing", NewStr: "x"}); err == nil {
+		t.Fatal("expected error for old_str with no match, got nil")
+	}
+}
+
+func TestApplyModifyEditInsertAfterLine(t *testing.T) {
+	lines := []string{"one", "two", "three"}
+	got, err := applyModifyEdit(lines, ModifyEdit{Type: "insert_after_line", Line: 1, Content: "inserted"})
+	if err != nil {
+		t.Fatalf("applyModifyEdit: %v", err)
+	}
+	want := []string{"one", "inserted", "two", "three"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApplyModifyEditInsertAtTop(t *testing.T) {
+	lines := []string{"one", "two"}
+	got, err := applyModifyEdit(lines, ModifyEdit{Type: "insert_after_line", Line: 0, Content: "top"})
+	if err != nil {
+		t.Fatalf("applyModifyEdit: %v", err)
+	}
+	want := []string{"top", "one", "two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApplyModifyEditInsertAfterLineOutOfRange(t *testing.T) {
+	lines := []string{"one", "two"}
+	if _, err := applyModifyEdit(lines, ModifyEdit{Type: "insert_after_line", Line: 5, Content: "x"}); err == nil {
+		t.Fatal("expected error for out-of-range line, got nil")
+	}
+}
+
+func TestApplyModifyEditDeleteRange(t *testing.T) {
+	lines := []string{"one", "two", "three", "four"}
+	got, err := applyModifyEdit(lines, ModifyEdit{Type: "delete_range", StartLine: 2, EndLine: 3})
+	if err != nil {
+		t.Fatalf("applyModifyEdit: %v", err)
+	}
+	want := []string{"one", "four"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestApplyModifyEditDeleteRangeOutOfRange(t *testing.T) {
+	lines := []string{"one", "two"}
+	if _, err := applyModifyEdit(lines, ModifyEdit{Type: "delete_range", StartLine: 2, EndLine: 5}); err == nil {
+		t.Fatal("expected error for out-of-range delete, got nil")
+	}
+}
+
+func TestApplyModifyEditUnknownType(t *testing.T) {
+	lines := []string{"one"}
+	if _, err := applyModifyEdit(lines, ModifyEdit{Type: "bogus"}); err == nil {
+		t.Fatal("expected error for unknown edit type, got nil")
+	}
+}
+
+func TestModifyFilePreservesTrailingNewline(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	path := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(path, []byte("line1\nline2\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	input, err := json.Marshal(ModifyFileInput{
+		Path:  "file.txt",
+		Edits: []ModifyEdit{{Type: "insert_after_line", Line: 0, Content: "top"}},
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if _, err := ModifyFile(input); err != nil {
+		t.Fatalf("ModifyFile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "top\nline1\nline2\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestModifyFileWithoutTrailingNewlineStaysWithout(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	path := filepath.Join(root, "file.txt")
+	if err := os.WriteFile(path, []byte("line1\nline2"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	input, err := json.Marshal(ModifyFileInput{
+		Path:  "file.txt",
+		Edits: []ModifyEdit{{Type: "insert_after_line", Line: 0, Content: "top"}},
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	if _, err := ModifyFile(input); err != nil {
+		t.Fatalf("ModifyFile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "top\nline1\nline2"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
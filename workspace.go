@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceRoot is the directory every tool call's file path is resolved
+// and sandboxed against. It defaults to the current directory and is set
+// once at startup via --workspace-root.
+var workspaceRoot = "."
+
+// setWorkspaceRoot validates and records the workspace root tool paths
+// will be resolved against.
+func setWorkspaceRoot(root string) error {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("resolving workspace root: %w", err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return fmt.Errorf("resolving workspace root: %w", err)
+	}
+	workspaceRoot = resolved
+	return nil
+}
+
+// resolveWorkspacePath resolves a tool-supplied path against workspaceRoot
+// and rejects anything that would escape it, whether via a ".." segment,
+// an absolute path pointing outside the root, or a symlink (at any existing
+// ancestor, including the final component) that targets outside the root.
+// ReadFile, ListFiles, EditFile, and ModifyFile all go through this before
+// touching disk.
+func resolveWorkspacePath(relPath string) (string, error) {
+	target := relPath
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(workspaceRoot, target)
+	}
+
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return "", fmt.Errorf("resolving path: %w", err)
+	}
+
+	resolved, err := resolveSymlinksLenient(abs)
+	if err != nil {
+		return "", fmt.Errorf("resolving path: %w", err)
+	}
+
+	rel, err := filepath.Rel(workspaceRoot, resolved)
+	if err != nil {
+		return "", fmt.Errorf("resolving path: %w", err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q escapes workspace root %q", relPath, workspaceRoot)
+	}
+
+	return resolved, nil
+}
+
+// resolveSymlinksLenient resolves symlinks in the existing portion of path,
+// even when the final component (or more) does not yet exist, as is the
+// case when a tool is about to create a new file. Any symlink found along
+// an existing prefix is followed, so a symlink planted inside the
+// workspace that points outside it cannot be used to bypass the sandbox.
+func resolveSymlinksLenient(path string) (string, error) {
+	suffix := ""
+	dir := path
+	for {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			return filepath.Join(resolved, suffix), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", err
+		}
+		suffix = filepath.Join(filepath.Base(dir), suffix)
+		dir = parent
+	}
+}
+
+// stringSetFlag implements flag.Value to collect a repeatable flag
+// (--allow-tool foo --allow-tool bar) into a set.
+type stringSetFlag struct {
+	values map[string]bool
+}
+
+func (s *stringSetFlag) String() string {
+	if s == nil || len(s.values) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (s *stringSetFlag) Set(v string) error {
+	if s.values == nil {
+		s.values = map[string]bool{}
+	}
+	s.values[v] = true
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withWorkspaceRoot(t *testing.T, root string) {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatalf("EvalSymlinks(%q): %v", root, err)
+	}
+	prev := workspaceRoot
+	workspaceRoot = resolved
+	t.Cleanup(func() { workspaceRoot = prev })
+}
+
+func TestResolveWorkspacePathWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	if err := os.WriteFile(filepath.Join(root, "file.txt"), []byte("hi"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := resolveWorkspacePath("file.txt")
+	if err != nil {
+		t.Fatalf("resolveWorkspacePath: %v", err)
+	}
+	if got != filepath.Join(root, "file.txt") {
+		t.Errorf("got %q, want %q", got, filepath.Join(root, "file.txt"))
+	}
+}
+
+func TestResolveWorkspacePathRejectsDotDotEscape(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	if _, err := resolveWorkspacePath("../outside.txt"); err == nil {
+		t.Fatal("expected error for path escaping workspace root via \"..\", got nil")
+	}
+}
+
+func TestResolveWorkspacePathRejectsAbsoluteEscape(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	outside := t.TempDir()
+	if _, err := resolveWorkspacePath(filepath.Join(outside, "secret.txt")); err == nil {
+		t.Fatal("expected error for absolute path outside workspace root, got nil")
+	}
+}
+
+func TestResolveWorkspacePathRejectsSymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	outside := t.TempDir()
+	target := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(target, []byte("top secret"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	link := filepath.Join(root, "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := resolveWorkspacePath("link.txt"); err == nil {
+		t.Fatal("expected error for symlink pointing outside workspace root, got nil")
+	}
+}
+
+func TestResolveWorkspacePathRejectsSymlinkEscapeForNewFile(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	outside := t.TempDir()
+	linkedDir := filepath.Join(root, "linked")
+	if err := os.Symlink(outside, linkedDir); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	// new-file.txt doesn't exist yet, but its parent directory is a
+	// symlink that escapes the workspace root, so this must still fail.
+	if _, err := resolveWorkspacePath("linked/new-file.txt"); err == nil {
+		t.Fatal("expected error for new file under a symlinked directory that escapes the workspace root, got nil")
+	}
+}
+
+func TestResolveWorkspacePathAllowsSymlinkWithinRoot(t *testing.T) {
+	root := t.TempDir()
+	withWorkspaceRoot(t, root)
+
+	realDir := filepath.Join(root, "real")
+	if err := os.Mkdir(realDir, 0o755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	linkedDir := filepath.Join(root, "linked")
+	if err := os.Symlink(realDir, linkedDir); err != nil {
+		t.Fatalf("Symlink: %v", err)
+	}
+
+	if _, err := resolveWorkspacePath("linked/new-file.txt"); err != nil {
+		t.Fatalf("resolveWorkspacePath: %v", err)
+	}
+}